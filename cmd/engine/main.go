@@ -7,24 +7,23 @@ import (
 	"os"
 	"os/signal"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/polyinsider/engine/internal/alert"
+	"github.com/polyinsider/engine/internal/bus"
+	"github.com/polyinsider/engine/internal/clock"
 	"github.com/polyinsider/engine/internal/config"
 	"github.com/polyinsider/engine/internal/detector"
+	"github.com/polyinsider/engine/internal/enrich"
 	"github.com/polyinsider/engine/internal/ingest"
 	"github.com/polyinsider/engine/internal/metrics"
+	"github.com/polyinsider/engine/internal/orderbook"
 	"github.com/polyinsider/engine/internal/store"
 	"github.com/polyinsider/engine/internal/ui"
 )
 
-const (
-	// TradeChannelBuffer is the size of the buffered trade channel
-	TradeChannelBuffer = 1000
-	// SuspectChannelBuffer is the size of the buffered suspect channel
-	SuspectChannelBuffer = 100
-)
-
 func main() {
 	// Load configuration
 	cfg, err := config.Load()
@@ -43,7 +42,9 @@ func main() {
 	)
 
 	slog.Info("config_loaded",
+		"run_mode", cfg.RunMode,
 		"polymarket_ws_url", cfg.PolymarketWSURL,
+		"ws_max_assets_per_conn", cfg.WSMaxAssetsPerConn,
 		"polymarket_rest_url", cfg.PolymarketRESTURL,
 		"enable_tui", cfg.EnableTUI,
 		"alchemy_key", cfg.MaskedAlchemyKey(),
@@ -53,9 +54,13 @@ func main() {
 		"fresh_wallet_nonce", cfg.FreshWalletNonce,
 		"burst_count", cfg.BurstCount,
 		"burst_window", cfg.BurstWindow,
+		"nonce_cache_ttl", cfg.NonceCacheTTL,
 		"worker_count", cfg.WorkerCount,
 		"db_path", cfg.DBPath,
 		"prometheus_port", cfg.PrometheusPort,
+		"market_refresh_interval", cfg.MarketRefreshInterval,
+		"market_tags", cfg.MarketTags,
+		"capture_dir", cfg.CaptureDir,
 	)
 
 	// Setup graceful shutdown
@@ -65,13 +70,106 @@ func main() {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
-	// Create channels
-	tradeChan := make(chan store.Trade, TradeChannelBuffer)
-	suspectChan := make(chan store.Suspect, SuspectChannelBuffer)
+	// Create event bus; every consumer gets its own subscription
+	eventBus := bus.New()
+	workerPolicy := mustParsePolicy(cfg.BusWorkerPolicy)
+	if cfg.RunMode == config.RunModeReplay {
+		// Replay must not lose trades to a full buffer; let workers apply backpressure
+		workerPolicy = bus.Block
+	}
+	sinkPolicy := mustParsePolicy(cfg.BusSinkPolicy)
+	// Trades are sharded to workers by asset so each asset is processed in order
+	workerSubs := eventBus.Trades.SubscribeSharded("workers", cfg.WorkerCount, cfg.BusBufferSize, workerPolicy,
+		func(trade store.Trade) string { return trade.AssetID })
+
+	// Open database and start batched writer.
+	// Sinks get their own context so they can flush after the pipeline stops.
+	db, err := store.Open(cfg.DBPath)
+	if err != nil {
+		slog.Error("failed to open database", "path", cfg.DBPath, "error", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	sinkCtx, stopSinks := context.WithCancel(context.Background())
+	writerSub := eventBus.Suspects.Subscribe("db_writer", cfg.BusBufferSize, sinkPolicy)
+	writer := store.NewWriter(db, writerSub.C())
+	writer.Start(sinkCtx)
+
+	// Start Discord notifier (optional)
+	var notifier *alert.Notifier
+	if cfg.DiscordWebhookURL != "" {
+		alertSub := eventBus.Suspects.Subscribe("discord", cfg.BusBufferSize, sinkPolicy)
+		notifier = alert.NewNotifier(alert.NewDiscordClient(cfg.DiscordWebhookURL), db,
+			alertSub.C(), cfg.AlertBatchDuration, cfg.AlertCooldown)
+		notifier.Start(sinkCtx)
+		slog.Info("discord_notifier_started", "batch_window", cfg.AlertBatchDuration, "cooldown", cfg.AlertCooldown)
+	}
 
 	// Initialize metrics tracker
-	tracker := metrics.NewMetricsTracker()
+	// Windows run on trade event time; the clock covers uptime, rates and missing timestamps
+	clk := clock.Real{}
+	tracker := metrics.NewMetricsTracker(clk)
+
+	// Initialize nonce enricher (Alchemy primary, public RPC fallback)
+	enricher := enrich.NewEnricherFromConfig(cfg)
+	enricher.SetObserver(tracker)
+
+	// Wallet age from first on-chain activity, searched in the background for fresh wallets.
+	// The search reads historical state, so it is turned off without an archive endpoint
+	ager := enrich.NewWalletAgerFromConfig(cfg)
+	if ager != nil {
+		if err := ager.Probe(ctx); err != nil {
+			slog.Warn("wallet_age_disabled", "reason", "no archive endpoint", "error", err)
+			ager = nil
+		}
+	}
+	if ager != nil {
+		ager.SetObserver(tracker)
+		ager.Start(ctx)
+	}
+
+	// Funding tracer groups flagged wallets by the address that first funded them
+	funding := enrich.NewFundingTracerFromConfig(cfg)
+	if funding != nil {
+		funding.SetObserver(tracker)
+		funding.SetStore(db)
+		fundings, err := db.LoadFundings()
+		if err != nil {
+			slog.Warn("funding_load_failed", "error", err)
+		}
+		funding.Load(fundings)
+		wallets, funders := funding.Stats()
+		slog.Info("funding_clusters_loaded", "wallets", wallets, "funders", funders)
+		funding.Start(ctx)
+	}
+
+	// Local L2 order books, fed by book and price_change events
+	books := orderbook.NewManager()
+
+	// Start Prometheus metrics endpoint
+	metrics.StartServer(ctx, cfg.PrometheusPort, tracker)
+
+	// Mirror bus drop counters and worker queue depths into metrics
+	go syncBusMetrics(ctx, eventBus, workerSubs, tracker)
 	
+	// Initialize detector
+	detect := detector.NewDetector(cfg, clk)
+	if cfg.RulesFile != "" {
+		rules, err := detector.LoadRulesFile(cfg.RulesFile)
+		if err != nil {
+			slog.Error("failed to load rules file", "path", cfg.RulesFile, "error", err)
+			os.Exit(1)
+		}
+		for _, rule := range rules {
+			if err := detect.Register(rule); err != nil {
+				slog.Error("failed to register rule", "rule", rule.Name(), "error", err)
+				os.Exit(1)
+			}
+		}
+	}
+	slog.Info("detector_rules", "rules", detect.Rules())
+
 	// Start periodic cleanup
 	go func() {
 		ticker := time.NewTicker(5 * time.Minute)
@@ -82,57 +180,136 @@ func main() {
 				return
 			case <-ticker.C:
 				tracker.Cleanup()
+				enricher.Cleanup()
+				if ager != nil {
+					ager.Cleanup()
+				}
+				if funding != nil {
+					funding.Cleanup()
+				}
+				detect.Cleanup()
+				logBusStats(eventBus)
+				logBookStats(books)
 			}
 		}
 	}()
 
-	// Initialize detector
-	detect := detector.NewDetector(cfg)
+	// Track market lifecycle events from discovery
+	marketSub := eventBus.Markets.Subscribe("metrics", cfg.BusBufferSize, bus.Block)
+	go consumeMarketEvents(marketSub, tracker, books)
 
-	// Fetch active market token IDs
-	slog.Info("fetching_active_markets")
-	markets, err := ingest.FetchActiveMarkets(100)
-	if err != nil {
-		slog.Warn("failed to fetch active markets, will subscribe to empty set", "error", err)
-		markets = []ingest.Market{}
-	}
-	tokenIDs := ingest.ExtractTokenIDs(markets)
+	// Every ingested trade is enriched with market metadata before it reaches the bus
+	registry := ingest.NewAssetRegistry()
+	// The WebSocket and REST poller report the same fills; drop repeats before enrichment
+	deduper := ingest.NewDeduper(registry.Wrap(eventBus.Trades), cfg.DedupWindow, cfg.DedupMaxEntries)
+	deduper.SetObserver(tracker)
+	tradeSink := deduper
 
-	// Initialize market activity in tracker
-	for _, market := range markets {
-		tracker.UpdateMarketActivity(market.ID, market.Question, 0, 0)
+	// Start worker pool to process trades; workers run until their channel
+	// closes, so shutdown can wait for the trades already queued
+	var workers sync.WaitGroup
+	for i, workerSub := range workerSubs {
+		workers.Add(1)
+		go func(id int, sub *bus.Subscription[store.Trade]) {
+			defer workers.Done()
+			worker(ctx, id, sub, eventBus.Suspects, detect, enricher, ager, funding, registry, tracker, cfg)
+		}(i, workerSub)
 	}
 
-	// Start WebSocket listener with active market tokens
-	listener := ingest.NewListener(cfg.PolymarketWSURL, tradeChan)
-	listener.SetAssetIDs(tokenIDs)
-	listener.Start(ctx)
-	tracker.SetWebSocketStatus("connected")
-
-	// Start REST API poller (optional - will fail gracefully if endpoint doesn't exist)
-	if cfg.PolymarketRESTURL != "" {
-		poller := ingest.NewTradesPoller(cfg.PolymarketRESTURL, cfg.TradePollInterval, tradeChan)
-		go poller.Start(ctx)
-		slog.Info("rest_poller_started", "url", cfg.PolymarketRESTURL, "interval", cfg.TradePollInterval)
-	}
+	// Live mode ingests from Polymarket; replay mode feeds a capture instead
+	var (
+		listener   *ingest.ListenerPool
+		capture    *ingest.Capture
+		replayDone chan struct{}
+	)
+	if cfg.RunMode == config.RunModeReplay {
+		replayer := ingest.NewReplayer(cfg.ReplayFile, tradeSink, cfg.ReplaySpeed)
+		replayer.SetBookSink(books)
+		replayer.SetRegistry(registry)
 
-	// Start worker pool to process trades
-	for i := 0; i < cfg.WorkerCount; i++ {
-		go worker(ctx, i, tradeChan, suspectChan, detect, tracker, cfg)
-	}
+		replayDone = make(chan struct{})
+		go func() {
+			defer close(replayDone)
+			if err := replayer.Run(ctx); err != nil {
+				slog.Error("replay_failed", "error", err)
+				return
+			}
+			// Every replayed trade, including those workers hold, is processed
+			// before shutdown cancels their lookups
+			eventBus.Trades.Close()
+			workers.Wait()
+		}()
 
-	slog.Info("engine_started", 
-		"status", "listening for trades", 
-		"subscribed_tokens", len(tokenIDs),
-		"workers", cfg.WorkerCount,
-		"tui_enabled", cfg.EnableTUI,
-	)
+		slog.Info("engine_started",
+			"status", "replaying capture",
+			"replay_file", cfg.ReplayFile,
+			"replay_speed", cfg.ReplaySpeed,
+			"workers", cfg.WorkerCount,
+			"tui_enabled", cfg.EnableTUI,
+		)
+	} else {
+		// Create sharded WebSocket listener pool; discovery decides what it subscribes to
+		listener = ingest.NewListenerPool(cfg.PolymarketWSURL, tradeSink, cfg.WSMaxAssetsPerConn)
+		listener.SetObserver(tracker)
+		listener.SetBookSink(books)
+
+		gamma := ingest.NewGammaClient(cfg.GammaAPIURL, cfg.GammaPageSize, ingest.MarketFilter{
+			Tags:          cfg.MarketTags,
+			MinVolume:     cfg.MarketMinVolumeUSD,
+			MinLiquidity:  cfg.MarketMinLiquidityUSD,
+			MinTimeToEnd:  time.Duration(cfg.MarketEndMinHours) * time.Hour,
+			MaxTimeToEnd:  time.Duration(cfg.MarketEndMaxHours) * time.Hour,
+			SlugAllowlist: cfg.MarketSlugAllowlist,
+			SlugDenylist:  cfg.MarketSlugDenylist,
+		})
+		fetchMarkets := gamma.FetchActiveMarkets
+
+		// Record raw frames and market snapshots for replay
+		if cfg.CaptureDir != "" {
+			capture, err = ingest.NewCapture(cfg.CaptureDir, int64(cfg.CaptureMaxMB)<<20)
+			if err != nil {
+				slog.Error("failed to start capture", "dir", cfg.CaptureDir, "error", err)
+				os.Exit(1)
+			}
+			listener.SetCapture(capture)
+			fetchMarkets = capture.WrapFetcher(fetchMarkets)
+		}
+
+		// Initial market discovery, then refresh periodically
+		slog.Info("fetching_active_markets")
+		discovery := ingest.NewDiscovery(fetchMarkets, listener, eventBus.Markets, cfg.MarketRefreshInterval)
+		discovery.SetRegistry(registry)
+		if err := discovery.Refresh(); err != nil {
+			slog.Warn("failed to fetch active markets, will subscribe to empty set", "error", err)
+		}
+		go discovery.Run(ctx)
+
+		listener.Start(ctx)
+
+		// Start REST API poller (optional - will fail gracefully if endpoint doesn't exist)
+		if cfg.PolymarketRESTURL != "" {
+			poller := ingest.NewTradesPoller(cfg.PolymarketRESTURL, cfg.TradePollInterval, tradeSink)
+			go poller.Start(ctx)
+			slog.Info("rest_poller_started", "url", cfg.PolymarketRESTURL, "interval", cfg.TradePollInterval)
+		}
+
+		slog.Info("engine_started",
+			"status", "listening for trades",
+			"subscribed_tokens", discovery.TokenCount(),
+			"ws_shards", listener.ShardCount(),
+			"workers", cfg.WorkerCount,
+			"tui_enabled", cfg.EnableTUI,
+		)
+	}
 
 	// Start TUI or run in background mode
 	if cfg.EnableTUI {
 		// TUI mode (blocking)
 		slog.Info("starting_tui")
-		app := ui.NewApp(tradeChan, suspectChan, tracker)
+		uiPolicy := mustParsePolicy(cfg.BusUIPolicy)
+		uiTradeSub := eventBus.Trades.Subscribe("ui_trades", cfg.BusBufferSize, uiPolicy)
+		uiSuspectSub := eventBus.Suspects.Subscribe("ui_suspects", cfg.BusBufferSize, uiPolicy)
+		app := ui.NewApp(uiTradeSub.C(), uiSuspectSub.C(), tracker)
 		
 		// Start TUI in goroutine so we can still handle signals
 		go func() {
@@ -151,27 +328,49 @@ func main() {
 			app.Stop()
 		}
 	} else {
-		// Background mode - just wait for signal
-		sig := <-sigChan
-		slog.Info("shutdown_signal_received", "signal", sig.String())
+		// Background mode - wait for signal, or for a replay to finish
+		select {
+		case sig := <-sigChan:
+			slog.Info("shutdown_signal_received", "signal", sig.String())
+		case <-replayDone:
+		}
 	}
 	
+	// Graceful shutdown: stop ingesting, let the workers finish queued trades
+	// with their lookups still live, then cancel everything else
+	if listener != nil {
+		slog.Info("shutting_down", "status", "stopping listener")
+		listener.Stop()
+	}
+	eventBus.Trades.Close()
+	waitForWorkers(&workers, workerDrainTimeout)
 	cancel()
 
-	// Graceful shutdown
-	slog.Info("shutting_down", "status", "stopping listener")
-	listener.Stop()
+	if capture != nil {
+		if err := capture.Close(); err != nil {
+			slog.Warn("capture_close_failed", "error", err)
+		}
+	}
 
-	// Drain remaining trades
-	drainTrades(tradeChan)
+	// Stop publishing
+	eventBus.Close()
+	logBusStats(eventBus)
+
+	// Flush pending DB writes and alerts
+	slog.Info("shutting_down", "status", "flushing db writer and alerts")
+	stopSinks()
+	writer.Wait()
+	if notifier != nil {
+		notifier.Wait()
+	}
 
 	slog.Info("shutdown_complete")
 }
 
 // worker processes trades, detects signals, and updates metrics.
-func worker(ctx context.Context, id int, tradeChan <-chan store.Trade, 
-	suspectChan chan<- store.Suspect, detect *detector.Detector, 
-	tracker *metrics.MetricsTracker, cfg *config.Config) {
+func worker(ctx context.Context, id int, trades *bus.Subscription[store.Trade], 
+	suspects *bus.Topic[store.Suspect], detect *detector.Detector, 
+	enricher *enrich.Enricher, ager *enrich.WalletAger, funding *enrich.FundingTracer, registry *ingest.AssetRegistry, tracker *metrics.MetricsTracker, cfg *config.Config) {
 	
 	slog.Debug("worker_started", "id", id)
 	defer slog.Debug("worker_stopped", "id", id)
@@ -180,70 +379,180 @@ func worker(ctx context.Context, id int, tradeChan <-chan store.Trade,
 		select {
 		case <-ctx.Done():
 			return
-		case trade, ok := <-tradeChan:
+		case trade, ok := <-trades.C():
 			if !ok {
 				return
 			}
 			
+			start := time.Now()
+			
 			// Update metrics
 			tracker.IncrementTrades()
-			tracker.RecordPrice(trade.MarketID, trade.Price)
-			
-			// Update market activity
-			tracker.UpdateMarketActivity(trade.MarketID, "", trade.Price, trade.ValueUSD)
-			
-			// Update channel buffer metrics
-			tracker.SetChannelBuffer(len(tradeChan), cap(tradeChan))
 			
 			// Track high-value trades
 			if trade.ValueUSD >= cfg.MinValueUSD {
 				tracker.IncrementHighValue()
 			}
 			
-			// Detect signals (no nonce yet, pass -1)
-			suspects := detect.Detect(trade, -1)
-			for _, suspect := range suspects {
+			// Enrich with wallet nonce only when the trade qualifies (-1 if unavailable)
+			enrichment := detector.Enrichment{Nonce: -1}
+			if detect.ShouldEnrich(trade) {
+				n, err := enricher.Nonce(ctx, trade.MakerAddress)
+				if err != nil {
+					slog.Debug("nonce_enrich_failed", "maker", truncateID(trade.MakerAddress), "error", err)
+				} else {
+					enrichment.Nonce = n
+				}
+			}
+			// Age only matters for fresh wallets. A search costs dozens of calls, so
+			// a miss is searched in the background and this trade goes by nonce alone
+			if ager != nil && enrichment.Nonce >= 0 && enrichment.Nonce <= cfg.FreshWalletNonce {
+				if activity, ok := ager.Lookup(trade.MakerAddress); ok {
+					enrichment.FirstActive = activity.At
+				}
+			}
+			if info, ok := registry.Lookup(trade.AssetID); ok {
+				enrichment.MarketVolume = info.Volume
+				enrichment.MarketLiquidity = info.Liquidity
+			}
+			enrichment.RecentVolume, enrichment.RecentTrades, enrichment.ValueRank =
+				tracker.RecentFlow(trade.MarketID, trade.ValueUSD)
+			
+			// Detect signals
+			detected := detect.Detect(trade, enrichment)
+			tracker.ObserveDetection(time.Since(start))
+			
+			// Update price history and market activity; wash trades are fake
+			// volume and stay out of Top Movers, including ones that raise no alert
+			if !detect.IsWash(trade) {
+				tracker.RecordPrice(trade.MarketID, trade.Price, trade.Timestamp)
+				tracker.UpdateMarketActivity(trade.MarketID, trade.Question, trade.Price, trade.ValueUSD, trade.Timestamp)
+			}
+			
+			// Attach the maker's funder and cluster to flagged trades. An unknown
+			// maker is traced in the background and the store attaches the result
+			if len(detected) > 0 && funding != nil && trade.MakerAddress != "" {
+				if f, size, ok := funding.Lookup(trade.MakerAddress); ok {
+					for i := range detected {
+						detected[i].Funder = f.Funder
+						detected[i].ClusterSize = size
+					}
+				}
+			}
+			
+			for _, suspect := range detected {
 				tracker.IncrementSignal(suspect.SignalType)
 				
-				// Send to suspect channel
-				select {
-				case suspectChan <- suspect:
-					slog.Debug("signal_detected", 
-						"type", suspect.SignalType, 
-						"market", truncateID(suspect.Trade.MarketID),
-						"value_usd", suspect.Trade.ValueUSD,
-					)
-				default:
-					slog.Warn("suspect_channel_full", "signal_type", suspect.SignalType)
-				}
+				// Publish to UI, DB writer and notifier
+				suspects.Publish(suspect)
+				slog.Debug("signal_detected", 
+					"type", suspect.SignalType, 
+					"market", truncateID(suspect.Trade.MarketID),
+					"value_usd", suspect.Trade.ValueUSD,
+				)
 			}
 		}
 	}
 }
 
-// drainTrades processes remaining trades in the channel during shutdown.
-func drainTrades(tradeChan <-chan store.Trade) {
-	timeout := time.After(5 * time.Second)
-	drained := 0
+// mustParsePolicy parses a bus overflow policy validated by config.Load.
+func mustParsePolicy(name string) bus.OverflowPolicy {
+	policy, err := bus.ParsePolicy(name)
+	if err != nil {
+		slog.Error("invalid bus overflow policy", "policy", name, "error", err)
+		os.Exit(1)
+	}
+	return policy
+}
 
+// syncBusMetrics periodically copies trade drop counters and worker queue
+// depths from the bus into the tracker.
+func syncBusMetrics(ctx context.Context, b *bus.Bus, workers []*bus.Subscription[store.Trade], tracker *metrics.MetricsTracker) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
 	for {
 		select {
-		case <-tradeChan:
-			drained++
-		case <-timeout:
-			if drained > 0 {
-				slog.Info("trades_drained", "count", drained)
-			}
+		case <-ctx.Done():
 			return
-		default:
-			if drained > 0 {
-				slog.Info("trades_drained", "count", drained)
+		case <-ticker.C:
+			for _, st := range b.Trades.Stats() {
+				tracker.SetDroppedTrades(st.Name, st.Dropped)
 			}
-			return
+
+			used, capacity := 0, 0
+			for _, sub := range workers {
+				tracker.SetWorkerQueue(sub.Name(), sub.Len(), sub.Cap())
+				used += sub.Len()
+				capacity += sub.Cap()
+			}
+			tracker.SetChannelBuffer(used, capacity)
+		}
+	}
+}
+
+// consumeMarketEvents feeds market added/closed events into the tracker and
+// drops the books of closed markets until the bus closes.
+func consumeMarketEvents(sub *bus.Subscription[store.MarketEvent], tracker *metrics.MetricsTracker, books *orderbook.Manager) {
+	for event := range sub.C() {
+		tracker.ObserveMarketEvent(event)
+		if event.Type == store.MarketClosed {
+			for _, tokenID := range event.TokenIDs {
+				books.Remove(tokenID)
+			}
+		}
+		slog.Debug("market_event", "type", event.Type, "market", event.MarketID, "question", event.Question)
+	}
+}
+
+// logBusStats logs subscribers that have dropped messages.
+func logBusStats(b *bus.Bus) {
+	for _, st := range b.Stats() {
+		if st.Dropped > 0 {
+			slog.Warn("bus_messages_dropped",
+				"topic", st.Topic,
+				"subscriber", st.Name,
+				"policy", st.Policy.String(),
+				"dropped", st.Dropped,
+			)
 		}
 	}
 }
 
+// logBookStats logs order book event counters.
+func logBookStats(books *orderbook.Manager) {
+	st := books.Stats()
+	slog.Info("orderbook_stats",
+		"books", st.Books,
+		"snapshots", st.Snapshots,
+		"changes", st.Changes,
+		"orphans", st.Orphans,
+		"stale", st.Stale,
+		"stale_books", st.StaleBooks,
+		"hash_verified", st.HashVerified,
+		"hash_mismatch", st.HashMismatch,
+	)
+}
+
+// workerDrainTimeout bounds how long shutdown waits for queued trades.
+const workerDrainTimeout = 10 * time.Second
+
+// waitForWorkers waits for the workers to process the trades queued before
+// the trade topic closed, giving up after timeout.
+func waitForWorkers(workers *sync.WaitGroup, timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		slog.Info("workers_drained")
+	case <-time.After(timeout):
+		slog.Warn("workers_drain_timeout", "timeout", timeout)
+	}
+}
+
 // truncateID shortens an ID for logging.
 func truncateID(id string) string {
 	if len(id) <= 12 {
@@ -0,0 +1,119 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/polyinsider/engine/internal/store"
+)
+
+// memoryRecorder collects recorded alerts for assertions.
+type memoryRecorder struct {
+	mu     sync.Mutex
+	alerts []store.Alert
+}
+
+func (r *memoryRecorder) InsertAlert(alert store.Alert) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.alerts = append(r.alerts, alert)
+	return nil
+}
+
+func TestDiscordRetryAfter(t *testing.T) {
+	var calls atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			json.NewEncoder(w).Encode(map[string]interface{}{"message": "slow down", "retry_after": 0.01})
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	client := NewDiscordClient(srv.URL)
+	if err := client.Send(context.Background(), WebhookPayload{Embeds: []Embed{{Title: "test"}}}); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("Expected 2 webhook calls, got %d", calls.Load())
+	}
+}
+
+func TestNotifierBatchesAndCooldown(t *testing.T) {
+	var payloads []WebhookPayload
+	var mu sync.Mutex
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p WebhookPayload
+		json.NewDecoder(r.Body).Decode(&p)
+		mu.Lock()
+		payloads = append(payloads, p)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	in := make(chan store.Suspect, 10)
+	rec := &memoryRecorder{}
+	n := NewNotifier(NewDiscordClient(srv.URL), rec, in, time.Hour, time.Hour)
+
+	trade := func(id string) store.Trade {
+		return store.Trade{ID: id, MakerAddress: "0xWallet", Side: "BUY", Price: 0.65, ValueUSD: 5420}
+	}
+
+	now := time.Now()
+	n.add(store.Suspect{Trade: trade("t1"), SignalType: store.SignalPanicBurst, Nonce: -1}, now)
+	n.add(store.Suspect{Trade: trade("t2"), SignalType: store.SignalFreshInsider, Nonce: 2}, now)
+	n.flush(context.Background(), n.order)
+
+	// Inside cooldown: suppressed
+	n.add(store.Suspect{Trade: trade("t3"), SignalType: store.SignalWhale, Nonce: -1}, now.Add(time.Minute))
+	if n.pendingCount != 0 {
+		t.Errorf("Expected suspect to be suppressed by cooldown, pending=%d", n.pendingCount)
+	}
+
+	if len(payloads) != 1 || len(payloads[0].Embeds) != 1 {
+		t.Fatalf("Expected 1 payload with 1 embed, got %+v", payloads)
+	}
+	embed := payloads[0].Embeds[0]
+	if embed.Title != "🔴 Fresh Insider Detected (2 trades)" {
+		t.Errorf("Unexpected title: %s", embed.Title)
+	}
+
+	if len(rec.alerts) != 1 {
+		t.Fatalf("Expected 1 recorded alert, got %d", len(rec.alerts))
+	}
+	if a := rec.alerts[0]; !a.Success || len(a.TradeIDs) != 2 || a.SignalType != store.SignalFreshInsider {
+		t.Errorf("Unexpected recorded alert: %+v", a)
+	}
+}
+
+func TestFormatUSD(t *testing.T) {
+	cases := map[float64]string{
+		5420:      "$5,420.00",
+		999.5:     "$999.50",
+		1234567.8: "$1,234,567.80",
+	}
+	for in, want := range cases {
+		if got := formatUSD(in); got != want {
+			t.Errorf("formatUSD(%v) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestFundingText(t *testing.T) {
+	const funder = "0xabcdef0000000000000000000000000000001234"
+	if got, want := fundingText(funder, 6), "Wallet is one of 6 funded by `0xabcd...1234`"; got != want {
+		t.Errorf("fundingText = %q, want %q", got, want)
+	}
+	if got, want := fundingText(funder, 1), "Funded by `0xabcd...1234`"; got != want {
+		t.Errorf("fundingText = %q, want %q", got, want)
+	}
+}
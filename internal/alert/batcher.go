@@ -0,0 +1,305 @@
+package alert
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/polyinsider/engine/internal/store"
+)
+
+// Alert batching per spec Section 4.3
+const (
+	// MaxPendingSuspects flushes all batches once this many suspects are queued
+	MaxPendingSuspects = 10
+	// flushCheckInterval is how often expired batch windows are checked
+	flushCheckInterval = time.Second
+	// shutdownFlushTimeout bounds the final flush on shutdown
+	shutdownFlushTimeout = 10 * time.Second
+)
+
+// Recorder persists alert attempts.
+type Recorder interface {
+	InsertAlert(alert store.Alert) error
+}
+
+// walletBatch accumulates suspects for one wallet within a batch window.
+type walletBatch struct {
+	key       string
+	wallet    string
+	suspects  []store.Suspect
+	firstSeen time.Time
+}
+
+// tradeIDs returns the distinct trade IDs in the batch, in arrival order.
+func (b *walletBatch) tradeIDs() []string {
+	seen := make(map[string]bool)
+	ids := make([]string, 0, len(b.suspects))
+	for _, s := range b.suspects {
+		if !seen[s.Trade.ID] {
+			seen[s.Trade.ID] = true
+			ids = append(ids, s.Trade.ID)
+		}
+	}
+	return ids
+}
+
+// totalValue sums the USD value of each distinct trade in the batch.
+func (b *walletBatch) totalValue() float64 {
+	seen := make(map[string]bool)
+	total := 0.0
+	for _, s := range b.suspects {
+		if !seen[s.Trade.ID] {
+			seen[s.Trade.ID] = true
+			total += s.Trade.ValueUSD
+		}
+	}
+	return total
+}
+
+// signalTypes returns the distinct signal types in priority order.
+func (b *walletBatch) signalTypes() []string {
+	seen := make(map[string]bool)
+	for _, s := range b.suspects {
+		seen[s.SignalType] = true
+	}
+
+	types := make([]string, 0, len(seen))
+	for _, signal := range signalPriority {
+		if seen[signal] {
+			types = append(types, signal)
+			delete(seen, signal)
+		}
+	}
+	for signal := range seen {
+		types = append(types, signal)
+	}
+	return types
+}
+
+// nonce returns the most recent known nonce in the batch, or -1.
+func (b *walletBatch) nonce() int {
+	for i := len(b.suspects) - 1; i >= 0; i-- {
+		if b.suspects[i].Nonce >= 0 {
+			return b.suspects[i].Nonce
+		}
+	}
+	return -1
+}
+
+// funding returns the most recent known funder in the batch and the size
+// of its cluster, or "" if none was traced.
+func (b *walletBatch) funding() (string, int) {
+	for i := len(b.suspects) - 1; i >= 0; i-- {
+		if b.suspects[i].Funder != "" {
+			return b.suspects[i].Funder, b.suspects[i].ClusterSize
+		}
+	}
+	return "", 0
+}
+
+// meta returns the latest value of a Meta key in the batch, or nil.
+func (b *walletBatch) meta(key string) interface{} {
+	for i := len(b.suspects) - 1; i >= 0; i-- {
+		if v, ok := b.suspects[i].Meta[key]; ok {
+			return v
+		}
+	}
+	return nil
+}
+
+// Notifier consumes suspects, batches them per wallet, and sends Discord alerts.
+type Notifier struct {
+	client      *DiscordClient
+	recorder    Recorder
+	in          <-chan store.Suspect
+	batchWindow time.Duration
+	cooldown    time.Duration
+
+	pending      map[string]*walletBatch
+	order        []string
+	pendingCount int
+	lastAlert    map[string]time.Time
+	done         chan struct{}
+}
+
+// NewNotifier creates a new Notifier. recorder may be nil.
+func NewNotifier(client *DiscordClient, recorder Recorder, in <-chan store.Suspect,
+	batchWindow, cooldown time.Duration) *Notifier {
+	return &Notifier{
+		client:      client,
+		recorder:    recorder,
+		in:          in,
+		batchWindow: batchWindow,
+		cooldown:    cooldown,
+		pending:     make(map[string]*walletBatch),
+		lastAlert:   make(map[string]time.Time),
+		done:        make(chan struct{}),
+	}
+}
+
+// Start begins consuming suspects. Pending batches are flushed when ctx is cancelled.
+func (n *Notifier) Start(ctx context.Context) {
+	go n.run(ctx)
+}
+
+// Wait blocks until the notifier has flushed its final batches.
+func (n *Notifier) Wait() {
+	<-n.done
+}
+
+// run is the notifier's main loop.
+func (n *Notifier) run(ctx context.Context) {
+	defer close(n.done)
+
+	ticker := time.NewTicker(flushCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			n.drain()
+			flushCtx, cancel := context.WithTimeout(context.Background(), shutdownFlushTimeout)
+			n.flush(flushCtx, n.order)
+			cancel()
+			return
+		case suspect, ok := <-n.in:
+			if !ok {
+				n.flush(ctx, n.order)
+				return
+			}
+			n.add(suspect, time.Now())
+			if n.pendingCount >= MaxPendingSuspects {
+				n.flush(ctx, n.order)
+			}
+		case now := <-ticker.C:
+			n.flush(ctx, n.expired(now))
+			n.cleanupCooldowns(now)
+		}
+	}
+}
+
+// drain queues any suspects already buffered in the input channel.
+func (n *Notifier) drain() {
+	for {
+		select {
+		case suspect, ok := <-n.in:
+			if !ok {
+				return
+			}
+			n.add(suspect, time.Now())
+		default:
+			return
+		}
+	}
+}
+
+// add queues a suspect into its wallet batch unless the wallet is cooling down.
+func (n *Notifier) add(suspect store.Suspect, now time.Time) {
+	wallet := suspect.Trade.MakerAddress
+	key := wallet
+	if key == "" {
+		// No wallet (e.g. book-derived price shocks): group by asset instead
+		key = "asset:" + suspect.Trade.AssetID
+	}
+
+	batch, exists := n.pending[key]
+	if !exists {
+		if last, ok := n.lastAlert[key]; ok && now.Sub(last) < n.cooldown {
+			slog.Debug("alert_suppressed_cooldown", "wallet", shortAddress(wallet), "signal_type", suspect.SignalType)
+			return
+		}
+		batch = &walletBatch{key: key, wallet: wallet, firstSeen: now}
+		n.pending[key] = batch
+		n.order = append(n.order, key)
+	}
+
+	batch.suspects = append(batch.suspects, suspect)
+	n.pendingCount++
+}
+
+// expired returns the keys of batches whose window has elapsed.
+func (n *Notifier) expired(now time.Time) []string {
+	var keys []string
+	for _, key := range n.order {
+		if now.Sub(n.pending[key].firstSeen) >= n.batchWindow {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// flush sends and removes the batches with the given keys.
+func (n *Notifier) flush(ctx context.Context, keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+
+	batches := make([]*walletBatch, 0, len(keys))
+	for _, key := range keys {
+		if batch, ok := n.pending[key]; ok {
+			batches = append(batches, batch)
+			delete(n.pending, key)
+			n.pendingCount -= len(batch.suspects)
+		}
+	}
+
+	remaining := n.order[:0]
+	for _, key := range n.order {
+		if _, ok := n.pending[key]; ok {
+			remaining = append(remaining, key)
+		}
+	}
+	n.order = remaining
+
+	for start := 0; start < len(batches); start += MaxEmbedsPerMessage {
+		end := min(start+MaxEmbedsPerMessage, len(batches))
+		n.send(ctx, batches[start:end])
+	}
+}
+
+// send delivers one Discord message and records an alert per batch.
+func (n *Notifier) send(ctx context.Context, batches []*walletBatch) {
+	payload := WebhookPayload{Embeds: make([]Embed, 0, len(batches))}
+	for _, batch := range batches {
+		payload.Embeds = append(payload.Embeds, FormatEmbed(batch))
+	}
+
+	err := n.client.Send(ctx, payload)
+	sentAt := time.Now()
+	if err != nil {
+		slog.Error("alert_failed", "webhook", "discord", "batches", len(batches), "error", err)
+	} else {
+		slog.Info("alert_sent", "webhook", "discord", "batches", len(batches))
+	}
+
+	for _, batch := range batches {
+		if err == nil {
+			n.lastAlert[batch.key] = sentAt
+		}
+
+		if n.recorder == nil {
+			continue
+		}
+		record := store.Alert{
+			TradeIDs:      batch.tradeIDs(),
+			WalletAddress: batch.wallet,
+			SignalType:    primarySignal(batch.suspects),
+			Summary:       FormatSummary(batch),
+			SentAt:        sentAt,
+			Success:       err == nil,
+		}
+		if recErr := n.recorder.InsertAlert(record); recErr != nil {
+			slog.Error("alert_record_failed", "error", recErr)
+		}
+	}
+}
+
+// cleanupCooldowns removes cooldown entries that have expired.
+func (n *Notifier) cleanupCooldowns(now time.Time) {
+	for key, last := range n.lastAlert {
+		if now.Sub(last) >= n.cooldown {
+			delete(n.lastAlert, key)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+// Package alert batches detection signals and delivers them to Discord.
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// MaxRetries is the number of times a rate-limited webhook call is retried
+	MaxRetries = 3
+	// MaxRetryAfter caps how long we honour a Discord retry_after
+	MaxRetryAfter = 60 * time.Second
+	// MaxEmbedsPerMessage is Discord's limit on embeds in a single message
+	MaxEmbedsPerMessage = 10
+)
+
+// WebhookPayload is the JSON body sent to a Discord webhook.
+type WebhookPayload struct {
+	Content string  `json:"content,omitempty"`
+	Embeds  []Embed `json:"embeds"`
+}
+
+// Embed is a Discord rich embed.
+type Embed struct {
+	Title     string       `json:"title"`
+	Color     int          `json:"color"`
+	Fields    []EmbedField `json:"fields"`
+	Timestamp string       `json:"timestamp,omitempty"`
+	Footer    *EmbedFooter `json:"footer,omitempty"`
+}
+
+// EmbedField is a single name/value field in an embed.
+type EmbedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+// EmbedFooter is the footer of an embed.
+type EmbedFooter struct {
+	Text string `json:"text"`
+}
+
+// rateLimitResponse is the body Discord returns with a 429.
+type rateLimitResponse struct {
+	Message    string  `json:"message"`
+	RetryAfter float64 `json:"retry_after"` // seconds
+	Global     bool    `json:"global"`
+}
+
+// DiscordClient posts payloads to a Discord webhook.
+type DiscordClient struct {
+	webhookURL string
+	client     *http.Client
+}
+
+// NewDiscordClient creates a new DiscordClient.
+func NewDiscordClient(webhookURL string) *DiscordClient {
+	return &DiscordClient{
+		webhookURL: webhookURL,
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Send posts the payload, waiting and retrying when Discord responds with 429.
+func (c *DiscordClient) Send(ctx context.Context, payload WebhookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal payload failed: %w", err)
+	}
+
+	for attempt := 0; ; attempt++ {
+		retryAfter, err := c.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+		if retryAfter == 0 || attempt >= MaxRetries {
+			return err
+		}
+
+		slog.Warn("discord_rate_limited", "retry_after", retryAfter, "attempt", attempt+1)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(retryAfter):
+		}
+	}
+}
+
+// post performs a single webhook request. A non-zero duration is returned
+// when the request was rate limited and may be retried after that long.
+func (c *DiscordClient) post(ctx context.Context, body []byte) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("create request failed: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return parseRetryAfter(resp), fmt.Errorf("rate limited")
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return 0, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+
+	return 0, nil
+}
+
+// parseRetryAfter reads retry_after from the 429 body, falling back to the
+// Retry-After header.
+func parseRetryAfter(resp *http.Response) time.Duration {
+	var wait time.Duration
+
+	var rl rateLimitResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rl); err == nil && rl.RetryAfter > 0 {
+		wait = time.Duration(rl.RetryAfter * float64(time.Second))
+	} else if secs, err := strconv.ParseFloat(resp.Header.Get("Retry-After"), 64); err == nil && secs > 0 {
+		wait = time.Duration(secs * float64(time.Second))
+	} else {
+		wait = time.Second
+	}
+
+	if wait > MaxRetryAfter {
+		wait = MaxRetryAfter
+	}
+	return wait
+}
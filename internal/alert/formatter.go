@@ -0,0 +1,219 @@
+package alert
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/polyinsider/engine/internal/store"
+)
+
+// FooterText is shown at the bottom of every embed.
+const FooterText = "Polyinsider v1.0"
+
+// signalStyle is the embed title and color for a signal type.
+type signalStyle struct {
+	title string
+	color int
+}
+
+// signalStyles maps signal types to their embed style (Appendix A).
+var signalStyles = map[string]signalStyle{
+	store.SignalFreshSwarm:    {"🐝 Fresh Wallet Swarm Detected", 10038562},
+	store.SignalFreshInsider:  {"🔴 Fresh Insider Detected", 15158332},
+	store.SignalWhale:         {"🐋 Whale Detected", 3447003},
+	store.SignalAccumulation:  {"🧺 Accumulation Detected", 15105570},
+	store.SignalRelativeWhale: {"🦈 Relative Whale Detected", 1752220},
+	store.SignalPanicBurst:    {"⚡ Panic Burst Detected", 15844367},
+	store.SignalPriceShock:    {"📈 Price Shock Detected", 3066993},
+	store.SignalVolumeAnomaly: {"📊 Volume Anomaly Detected", 10181046},
+	store.SignalWashTrade:     {"🔁 Wash Trading Detected", 9936031},
+}
+
+// signalPriority orders signal types from most to least important.
+var signalPriority = []string{
+	store.SignalFreshSwarm,
+	store.SignalFreshInsider,
+	store.SignalAccumulation,
+	store.SignalWhale,
+	store.SignalRelativeWhale,
+	store.SignalPanicBurst,
+	store.SignalWashTrade,
+	store.SignalVolumeAnomaly,
+	store.SignalPriceShock,
+}
+
+// FormatEmbed renders a batch of suspects for one wallet as a Discord embed.
+func FormatEmbed(b *walletBatch) Embed {
+	primary := primarySignal(b.suspects)
+	style, ok := signalStyles[primary]
+	if !ok {
+		style = signalStyle{fmt.Sprintf("❓ %s Detected", primary), 9807270}
+	}
+
+	latest := b.suspects[len(b.suspects)-1]
+	trade := latest.Trade
+
+	title := style.title
+	tradeIDs := b.tradeIDs()
+	if len(tradeIDs) > 1 {
+		title = fmt.Sprintf("%s (%d trades)", title, len(tradeIDs))
+	}
+
+	fields := []EmbedField{
+		{Name: "Wallet", Value: fmt.Sprintf("`%s`", shortAddress(b.wallet)), Inline: true},
+	}
+	if nonce := b.nonce(); nonce >= 0 {
+		fields = append(fields, EmbedField{Name: "Nonce", Value: fmt.Sprintf("%d", nonce), Inline: true})
+	}
+	if days, ok := b.meta("wallet_age_days").(float64); ok {
+		fields = append(fields, EmbedField{Name: "Wallet Age", Value: fmt.Sprintf("%.1f days", days), Inline: true})
+	}
+	fields = append(fields,
+		EmbedField{Name: "Value", Value: formatUSD(b.totalValue()), Inline: true},
+		EmbedField{Name: "Market", Value: marketName(trade), Inline: false},
+		EmbedField{Name: "Side", Value: formatSide(trade), Inline: true},
+	)
+	if net, ok := b.meta("net_usd").(float64); ok {
+		trades, _ := b.meta("trades").(int)
+		fields = append(fields, EmbedField{Name: "Position", Value: fmt.Sprintf("%s over %d trades", formatUSD(net), trades), Inline: true})
+	}
+	if count, ok := b.meta("wallet_count").(int); ok {
+		combined, _ := b.meta("combined_usd").(float64)
+		fields = append(fields, EmbedField{Name: "Swarm", Value: fmt.Sprintf("%d fresh wallets, %s combined", count, formatUSD(combined)), Inline: false})
+	}
+	if counterparty, ok := b.meta("counterparty").(string); ok {
+		fields = append(fields, EmbedField{Name: "Counterparty", Value: fmt.Sprintf("`%s`", shortAddress(counterparty)), Inline: true})
+	}
+	if multiple, ok := b.meta("volume_multiple").(float64); ok {
+		fields = append(fields, EmbedField{Name: "Activity", Value: fmt.Sprintf("%.0fx normal volume", multiple), Inline: true})
+	}
+	if funder, size := b.funding(); funder != "" {
+		fields = append(fields, EmbedField{Name: "Funding", Value: fundingText(funder, size), Inline: false})
+	}
+	if signals := b.signalTypes(); len(signals) > 1 {
+		fields = append(fields, EmbedField{Name: "Signals", Value: strings.Join(signals, ", "), Inline: false})
+	}
+
+	ts := trade.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+
+	return Embed{
+		Title:     title,
+		Color:     style.color,
+		Fields:    fields,
+		Timestamp: ts.UTC().Format("2006-01-02T15:04:05.000Z"),
+		Footer:    &EmbedFooter{Text: FooterText},
+	}
+}
+
+// FormatSummary renders a one-line plain-text summary for the alert history.
+func FormatSummary(b *walletBatch) string {
+	return fmt.Sprintf("%s wallet=%s trades=%d value=%s market=%s",
+		strings.Join(b.signalTypes(), ","),
+		shortAddress(b.wallet),
+		len(b.tradeIDs()),
+		formatUSD(b.totalValue()),
+		marketName(b.suspects[len(b.suspects)-1].Trade),
+	)
+}
+
+// primarySignal returns the highest-priority signal type in the batch.
+func primarySignal(suspects []store.Suspect) string {
+	seen := make(map[string]bool)
+	for _, s := range suspects {
+		seen[s.SignalType] = true
+	}
+	for _, signal := range signalPriority {
+		if seen[signal] {
+			return signal
+		}
+	}
+
+	// Signals from other rules: the most severe wins
+	primary := suspects[0]
+	for _, s := range suspects[1:] {
+		if severityRank[s.Severity] > severityRank[primary.Severity] {
+			primary = s
+		}
+	}
+	return primary.SignalType
+}
+
+// severityRank orders suspect severities; unknown severities rank lowest.
+var severityRank = map[string]int{
+	store.SeverityLow:      1,
+	store.SeverityMedium:   2,
+	store.SeverityHigh:     3,
+	store.SeverityCritical: 4,
+}
+
+// marketName returns the best available human-readable market label.
+func marketName(trade store.Trade) string {
+	if trade.Question != "" {
+		return trade.Question
+	}
+	if trade.MarketID != "" {
+		return trade.MarketID
+	}
+	if trade.AssetID != "" {
+		return shortAddress(trade.AssetID)
+	}
+	return "unknown"
+}
+
+// formatSide formats a trade as e.g. "BUY YES @ 0.65".
+func formatSide(trade store.Trade) string {
+	parts := make([]string, 0, 3)
+	if trade.Side != "" {
+		parts = append(parts, trade.Side)
+	}
+	if trade.Outcome != "" {
+		parts = append(parts, trade.Outcome)
+	}
+	parts = append(parts, fmt.Sprintf("@ %.2f", trade.Price))
+	return strings.Join(parts, " ")
+}
+
+// fundingText describes a wallet's funder and how many wallets it funded.
+func fundingText(funder string, clusterSize int) string {
+	if clusterSize > 1 {
+		return fmt.Sprintf("Wallet is one of %d funded by `%s`", clusterSize, shortAddress(funder))
+	}
+	return fmt.Sprintf("Funded by `%s`", shortAddress(funder))
+}
+
+// shortAddress truncates an address for display.
+func shortAddress(addr string) string {
+	if addr == "" {
+		return "unknown"
+	}
+	if len(addr) <= 12 {
+		return addr
+	}
+	return addr[:6] + "..." + addr[len(addr)-4:]
+}
+
+// formatUSD formats a value as "$5,420.00".
+func formatUSD(v float64) string {
+	s := fmt.Sprintf("%.2f", v)
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	intPart, frac := s[:len(s)-3], s[len(s)-3:]
+	var b strings.Builder
+	for i, r := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+
+	out := "$" + b.String() + frac
+	if neg {
+		out = "-" + out
+	}
+	return out
+}
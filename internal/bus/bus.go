@@ -0,0 +1,315 @@
+// Package bus provides an in-process publish/subscribe event bus.
+package bus
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/polyinsider/engine/internal/store"
+)
+
+// OverflowPolicy controls what happens when a subscriber's buffer is full.
+type OverflowPolicy int
+
+const (
+	// Block makes the publisher wait until the subscriber has room
+	Block OverflowPolicy = iota
+	// DropNewest discards the message being published
+	DropNewest
+	// DropOldest discards the oldest buffered message to make room
+	DropOldest
+)
+
+// String returns the config name of the policy.
+func (p OverflowPolicy) String() string {
+	switch p {
+	case Block:
+		return "block"
+	case DropNewest:
+		return "drop_newest"
+	case DropOldest:
+		return "drop_oldest"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(p))
+	}
+}
+
+// ParsePolicy parses a policy name ("block", "drop_newest", "drop_oldest").
+func ParsePolicy(s string) (OverflowPolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "block":
+		return Block, nil
+	case "drop_newest", "drop-newest":
+		return DropNewest, nil
+	case "drop_oldest", "drop-oldest":
+		return DropOldest, nil
+	default:
+		return Block, fmt.Errorf("unknown overflow policy %q", s)
+	}
+}
+
+// SubscriberStats is a point-in-time view of one subscription.
+type SubscriberStats struct {
+	Topic   string
+	Name    string
+	Policy  OverflowPolicy
+	Len     int
+	Cap     int
+	Dropped uint64
+}
+
+// Subscription is a buffered view of a topic for a single consumer.
+type Subscription[T any] struct {
+	name     string
+	policy   OverflowPolicy
+	ch       chan T
+	done     chan struct{}
+	doneOnce sync.Once
+	sendMu   sync.Mutex // serializes drop-oldest evict+send
+	dropped  atomic.Uint64
+	filter   func(T) bool // nil accepts every message
+	topic    *Topic[T]
+}
+
+// C returns the channel to receive messages from. It is closed on Unsubscribe or topic Close.
+func (s *Subscription[T]) C() <-chan T {
+	return s.ch
+}
+
+// Name returns the subscriber name.
+func (s *Subscription[T]) Name() string {
+	return s.name
+}
+
+// Dropped returns the number of messages dropped for this subscriber.
+func (s *Subscription[T]) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Len returns the number of buffered messages.
+func (s *Subscription[T]) Len() int {
+	return len(s.ch)
+}
+
+// Cap returns the buffer capacity.
+func (s *Subscription[T]) Cap() int {
+	return cap(s.ch)
+}
+
+// Unsubscribe removes the subscription from its topic and closes its channel.
+func (s *Subscription[T]) Unsubscribe() {
+	s.topic.remove(s)
+}
+
+// stop unblocks any publisher waiting on this subscription.
+func (s *Subscription[T]) stop() {
+	s.doneOnce.Do(func() { close(s.done) })
+}
+
+// deliver sends msg according to the subscription's overflow policy.
+func (s *Subscription[T]) deliver(msg T) {
+	switch s.policy {
+	case Block:
+		select {
+		case s.ch <- msg:
+		case <-s.done:
+			s.dropped.Add(1)
+		}
+
+	case DropOldest:
+		s.sendMu.Lock()
+		defer s.sendMu.Unlock()
+		for {
+			select {
+			case s.ch <- msg:
+				return
+			default:
+			}
+			select {
+			case <-s.ch:
+				s.dropped.Add(1)
+			default:
+			}
+		}
+
+	default: // DropNewest
+		select {
+		case s.ch <- msg:
+		default:
+			s.dropped.Add(1)
+		}
+	}
+}
+
+// Topic fans out published messages of type T to every subscriber.
+type Topic[T any] struct {
+	name   string
+	mu     sync.RWMutex
+	subs   []*Subscription[T]
+	closed bool
+}
+
+// NewTopic creates a new Topic.
+func NewTopic[T any](name string) *Topic[T] {
+	return &Topic[T]{name: name}
+}
+
+// Subscribe registers a new subscriber with its own buffer and overflow policy.
+func (t *Topic[T]) Subscribe(name string, buffer int, policy OverflowPolicy) *Subscription[T] {
+	return t.subscribe(name, buffer, policy, nil)
+}
+
+// SubscribeSharded registers shards subscribers named "name-0" to "name-N"
+// that split the topic between them by key: every message with the same key
+// goes to the same shard, in publish order. Each shard has its own buffer.
+func (t *Topic[T]) SubscribeSharded(name string, shards, buffer int, policy OverflowPolicy, key func(T) string) []*Subscription[T] {
+	if shards < 1 {
+		shards = 1
+	}
+	subs := make([]*Subscription[T], shards)
+	for i := range subs {
+		shard := i
+		subs[i] = t.subscribe(fmt.Sprintf("%s-%d", name, i), buffer, policy, func(msg T) bool {
+			return ShardOf(key(msg), shards) == shard
+		})
+	}
+	return subs
+}
+
+// ShardOf maps key to one of n shards.
+func ShardOf(key string, n int) int {
+	if n <= 1 {
+		return 0
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % uint32(n))
+}
+
+// subscribe registers a subscriber that receives the messages filter accepts.
+func (t *Topic[T]) subscribe(name string, buffer int, policy OverflowPolicy, filter func(T) bool) *Subscription[T] {
+	sub := &Subscription[T]{
+		name:   name,
+		policy: policy,
+		ch:     make(chan T, buffer),
+		done:   make(chan struct{}),
+		filter: filter,
+		topic:  t,
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		sub.stop()
+		close(sub.ch)
+		return sub
+	}
+	t.subs = append(t.subs, sub)
+	return sub
+}
+
+// Publish delivers msg to every subscriber.
+func (t *Topic[T]) Publish(msg T) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if t.closed {
+		return
+	}
+	for _, sub := range t.subs {
+		if sub.filter != nil && !sub.filter(msg) {
+			continue
+		}
+		sub.deliver(msg)
+	}
+}
+
+// Stats returns per-subscriber buffer and drop statistics.
+func (t *Topic[T]) Stats() []SubscriberStats {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	stats := make([]SubscriberStats, 0, len(t.subs))
+	for _, sub := range t.subs {
+		stats = append(stats, SubscriberStats{
+			Topic:   t.name,
+			Name:    sub.name,
+			Policy:  sub.policy,
+			Len:     len(sub.ch),
+			Cap:     cap(sub.ch),
+			Dropped: sub.dropped.Load(),
+		})
+	}
+	return stats
+}
+
+// Close closes every subscription. Further publishes are ignored.
+func (t *Topic[T]) Close() {
+	// Unblock publishers waiting on Block subscribers before taking the write lock
+	t.mu.RLock()
+	for _, sub := range t.subs {
+		sub.stop()
+	}
+	t.mu.RUnlock()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return
+	}
+	t.closed = true
+	for _, sub := range t.subs {
+		close(sub.ch)
+	}
+	t.subs = nil
+}
+
+// remove unsubscribes sub from the topic.
+func (t *Topic[T]) remove(sub *Subscription[T]) {
+	sub.stop()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i, s := range t.subs {
+		if s == sub {
+			t.subs = append(t.subs[:i], t.subs[i+1:]...)
+			close(sub.ch)
+			return
+		}
+	}
+}
+
+// Bus groups the topics used by the engine.
+type Bus struct {
+	Trades   *Topic[store.Trade]
+	Suspects *Topic[store.Suspect]
+	Markets  *Topic[store.MarketEvent]
+}
+
+// New creates a new Bus.
+func New() *Bus {
+	return &Bus{
+		Trades:   NewTopic[store.Trade]("trades"),
+		Suspects: NewTopic[store.Suspect]("suspects"),
+		Markets:  NewTopic[store.MarketEvent]("markets"),
+	}
+}
+
+// Stats returns subscriber statistics for every topic.
+func (b *Bus) Stats() []SubscriberStats {
+	stats := append(b.Trades.Stats(), b.Suspects.Stats()...)
+	return append(stats, b.Markets.Stats()...)
+}
+
+// Close closes all topics.
+func (b *Bus) Close() {
+	b.Trades.Close()
+	b.Suspects.Close()
+	b.Markets.Close()
+}
@@ -0,0 +1,115 @@
+package bus
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestEverySubscriberSeesEveryMessage(t *testing.T) {
+	topic := NewTopic[int]("test")
+	a := topic.Subscribe("a", 10, Block)
+	b := topic.Subscribe("b", 10, Block)
+
+	for i := 0; i < 5; i++ {
+		topic.Publish(i)
+	}
+
+	for _, sub := range []*Subscription[int]{a, b} {
+		if sub.Len() != 5 {
+			t.Errorf("Subscriber %s expected 5 messages, got %d", sub.Name(), sub.Len())
+		}
+	}
+}
+
+func TestShardedSubscriptionKeepsKeyOrder(t *testing.T) {
+	topic := NewTopic[string]("test")
+	shards := topic.SubscribeSharded("workers", 4, 100, Block, func(msg string) string { return msg[:1] })
+
+	// Keys a-h, each published three times in sequence
+	for round := 0; round < 3; round++ {
+		for key := 'a'; key <= 'h'; key++ {
+			topic.Publish(fmt.Sprintf("%c%d", key, round))
+		}
+	}
+
+	total := 0
+	for i, sub := range shards {
+		if want := fmt.Sprintf("workers-%d", i); sub.Name() != want {
+			t.Errorf("Expected shard name %s, got %s", want, sub.Name())
+		}
+		next := make(map[byte]int)
+		for sub.Len() > 0 {
+			msg := <-sub.C()
+			if ShardOf(msg[:1], 4) != i {
+				t.Errorf("Message %s delivered to shard %d", msg, i)
+			}
+			if round := int(msg[1] - '0'); round != next[msg[0]] {
+				t.Errorf("Message %s out of order on shard %d", msg, i)
+			}
+			next[msg[0]]++
+			total++
+		}
+	}
+	if total != 24 {
+		t.Errorf("Expected every message delivered to exactly one shard, got %d", total)
+	}
+}
+
+func TestOverflowPolicies(t *testing.T) {
+	topic := NewTopic[int]("test")
+	newest := topic.Subscribe("newest", 2, DropNewest)
+	oldest := topic.Subscribe("oldest", 2, DropOldest)
+
+	for i := 1; i <= 4; i++ {
+		topic.Publish(i)
+	}
+
+	if got := []int{<-newest.C(), <-newest.C()}; got[0] != 1 || got[1] != 2 {
+		t.Errorf("drop_newest expected [1 2], got %v", got)
+	}
+	if got := []int{<-oldest.C(), <-oldest.C()}; got[0] != 3 || got[1] != 4 {
+		t.Errorf("drop_oldest expected [3 4], got %v", got)
+	}
+	if newest.Dropped() != 2 || oldest.Dropped() != 2 {
+		t.Errorf("Expected 2 drops each, got newest=%d oldest=%d", newest.Dropped(), oldest.Dropped())
+	}
+}
+
+func TestBlockUnblocksOnClose(t *testing.T) {
+	topic := NewTopic[int]("test")
+	topic.Subscribe("slow", 1, Block)
+	topic.Publish(1)
+
+	published := make(chan struct{})
+	go func() {
+		topic.Publish(2) // blocks: buffer full
+		close(published)
+	}()
+
+	select {
+	case <-published:
+		t.Fatal("Expected publish to block while buffer is full")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	topic.Close()
+
+	select {
+	case <-published:
+	case <-time.After(time.Second):
+		t.Fatal("Publish still blocked after Close")
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	for in, want := range map[string]OverflowPolicy{"block": Block, "drop_newest": DropNewest, "DROP_OLDEST": DropOldest} {
+		got, err := ParsePolicy(in)
+		if err != nil || got != want {
+			t.Errorf("ParsePolicy(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	if _, err := ParsePolicy("bogus"); err == nil {
+		t.Error("Expected error for unknown policy")
+	}
+}
@@ -0,0 +1,39 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWatermarkToleratesLateness(t *testing.T) {
+	start := time.Unix(1_700_000_000, 0)
+	w := NewWatermark(5 * time.Second)
+
+	if !w.Current().IsZero() {
+		t.Fatalf("Expected zero watermark before any event")
+	}
+
+	w.Observe(start.Add(10 * time.Second))
+	if late := w.Observe(start.Add(6 * time.Second)); late {
+		t.Errorf("Expected event within lateness to be on time")
+	}
+	if late := w.Observe(start.Add(4 * time.Second)); !late {
+		t.Errorf("Expected event beyond lateness to be late")
+	}
+	if got := w.Current(); !got.Equal(start.Add(5 * time.Second)) {
+		t.Errorf("Expected watermark to trail the newest event by 5s, got %v", got)
+	}
+}
+
+func TestManualClockAndEventTime(t *testing.T) {
+	start := time.Unix(1_700_000_000, 0)
+	c := NewManual(start)
+	c.Advance(time.Minute)
+
+	if got := EventTime(time.Time{}, c); !got.Equal(start.Add(time.Minute)) {
+		t.Errorf("Expected missing timestamp to fall back to the clock, got %v", got)
+	}
+	if got := EventTime(start, c); !got.Equal(start) {
+		t.Errorf("Expected event timestamp to win, got %v", got)
+	}
+}
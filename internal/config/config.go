@@ -5,31 +5,127 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
+
+	"github.com/polyinsider/engine/internal/bus"
+)
+
+// Run modes
+const (
+	RunModeLive   = "live"
+	RunModeReplay = "replay"
 )
 
 // Config holds all configuration values for the Polyinsider engine.
 type Config struct {
+	// Run mode: live ingest, or replay of a capture file
+	RunMode     string
+	ReplayFile  string
+	ReplaySpeed float64
+
+	// Raw frame capture (disabled when CaptureDir is empty)
+	CaptureDir   string
+	CaptureMaxMB int
+
 	// Polymarket WebSocket
-	PolymarketWSURL string
+	PolymarketWSURL    string
+	WSMaxAssetsPerConn int
 
 	// Polymarket REST API
 	PolymarketRESTURL string
 	TradePollInterval time.Duration
 
+	// Cross-source deduplication
+	DedupWindow     time.Duration
+	DedupMaxEntries int
+
+	// Market Discovery
+	GammaAPIURL           string
+	GammaPageSize         int
+	MarketRefreshInterval time.Duration
+	MarketTags            []string
+	MarketMinVolumeUSD    float64
+	MarketMinLiquidityUSD float64
+	MarketEndMinHours     int
+	MarketEndMaxHours     int
+	MarketSlugAllowlist   []string
+	MarketSlugDenylist    []string
+
 	// Blockchain RPC
 	AlchemyAPIKey  string
 	AlchemyURL     string
 	FallbackRPCURL string
 
+	// Enrichment
+	RPCTimeout          time.Duration
+	NonceCacheTTL       time.Duration
+	RPCBreakerThreshold int
+	RPCBreakerCooldown  time.Duration
+
+	// Funding tracing: FundingRPCURL must serve alchemy_getAssetTransfers;
+	// empty means the Alchemy endpoint
+	FundingTraceEnabled bool
+	FundingRPCURL       string
+
+	// Wallet age: first activity found by binary search over archive state
+	WalletAgeEnabled bool
+
 	// Detection Thresholds
-	MinValueUSD      float64
-	WhaleValueUSD    float64
-	FreshWalletNonce int
-	BurstCount       int
-	BurstWindow      time.Duration
+	MinValueUSD       float64
+	WhaleValueUSD     float64
+	FreshWalletNonce  int
+	FreshWalletMaxAge time.Duration // 0 judges fresh wallets by nonce only
+	BurstCount        int
+	BurstWindow       time.Duration
+
+	// Price shock: a move of at least Pct (relative) and Points (probability)
+	// within Window, on a trade worth at least MinUSD. 0 disables a threshold.
+	PriceShockPct    float64
+	PriceShockPoints float64
+	PriceShockWindow time.Duration
+	PriceShockMinUSD float64
+
+	// Relative whale: trades large for their market. A fraction of 0 disables that check.
+	RelativeWhaleMinUSD            float64
+	RelativeWhaleVolumeFraction    float64 // of the market's lifetime volume
+	RelativeWhaleLiquidityFraction float64 // of the market's liquidity
+	RelativeWhaleRecentFraction    float64 // of the market's traded volume in the last hour
+	RelativeWhalePercentile        float64 // of trade values in the market in the last hour
+	RelativeWhaleMinTrades         int     // recent trades needed for the rolling checks
+
+	// Accumulation: a wallet's net exposure to one asset within the window
+	// reaching WhaleValueUSD across several trades
+	AccumulationWindow      time.Duration
+	AccumulationMinTradeUSD float64 // smaller trades are not tracked
+
+	// Fresh swarm: MinWallets distinct wallets at or under FreshWalletNonce
+	// trading the same side of one asset within Window. 0 wallets disables it.
+	FreshSwarmMinWallets  int
+	FreshSwarmWindow      time.Duration
+	FreshSwarmMinTradeUSD float64 // smaller trades are not looked up or counted
+
+	// Wash trades: maker equal to taker, or two wallets trading one asset
+	// back and forth MinRoundTrips times within Window
+	WashWindow        time.Duration
+	WashMinRoundTrips int
+
+	// Volume anomaly: per-minute volume or trade count Z standard deviations
+	// above an EWMA baseline. A Z of 0 disables the rule.
+	VolumeAnomalyZ          float64
+	VolumeAnomalyAlpha      float64 // EWMA weight of each minute
+	VolumeAnomalyMinMinutes int     // minutes of history before a market can fire
+	VolumeAnomalyMinUSD     float64 // USD the minute must reach before it can fire
+
+	// Event time: how far out of order trades may arrive and still be windowed exactly
+	EventLateness time.Duration
+
+	// Detection rules by signal name; an enabled list keeps only those rules
+	RulesEnabled  []string
+	RulesDisabled []string
+	RulesFile     string // JSON file of expression rules, empty for none
 
 	// Alerting
 	DiscordWebhookURL  string
@@ -42,6 +138,12 @@ type Config struct {
 	// Workers
 	WorkerCount int
 
+	// Event Bus
+	BusBufferSize   int
+	BusWorkerPolicy string
+	BusUIPolicy     string
+	BusSinkPolicy   string
+
 	// Metrics
 	PrometheusPort int
 
@@ -60,22 +162,103 @@ func Load() (*Config, error) {
 	_ = godotenv.Load()
 
 	cfg := &Config{
+		// Run mode
+		RunMode:     strings.ToLower(getEnv("RUN_MODE", RunModeLive)),
+		ReplayFile:  getEnv("REPLAY_FILE", ""),
+		ReplaySpeed: getEnvFloat("REPLAY_SPEED", 1),
+
+		// Capture
+		CaptureDir:   getEnv("CAPTURE_DIR", ""),
+		CaptureMaxMB: getEnvInt("CAPTURE_MAX_MB", 100),
+
 		// Polymarket
-		PolymarketWSURL:   getEnv("POLYMARKET_WS_URL", "wss://ws-subscriptions-clob.polymarket.com/ws/"),
-		PolymarketRESTURL: getEnv("POLYMARKET_REST_URL", "https://clob.polymarket.com"),
-		TradePollInterval: time.Duration(getEnvInt("TRADE_POLL_INTERVAL_SECONDS", 3)) * time.Second,
+		PolymarketWSURL:    getEnv("POLYMARKET_WS_URL", "wss://ws-subscriptions-clob.polymarket.com/ws/"),
+		WSMaxAssetsPerConn: getEnvInt("WS_MAX_ASSETS_PER_CONN", 500),
+		PolymarketRESTURL:  getEnv("POLYMARKET_REST_URL", "https://clob.polymarket.com"),
+		TradePollInterval:  time.Duration(getEnvInt("TRADE_POLL_INTERVAL_SECONDS", 3)) * time.Second,
+
+		// Deduplication
+		DedupWindow:     time.Duration(getEnvInt("DEDUP_WINDOW_SECONDS", 120)) * time.Second,
+		DedupMaxEntries: getEnvInt("DEDUP_MAX_ENTRIES", 100000),
+
+		// Market Discovery
+		GammaAPIURL:           getEnv("GAMMA_API_URL", "https://gamma-api.polymarket.com"),
+		GammaPageSize:         getEnvInt("GAMMA_PAGE_SIZE", 100),
+		MarketRefreshInterval: time.Duration(getEnvInt("MARKET_REFRESH_INTERVAL_SECONDS", 300)) * time.Second,
+		MarketTags:            getEnvList("MARKET_TAGS"),
+		MarketMinVolumeUSD:    getEnvFloat("MARKET_MIN_VOLUME_USD", 0),
+		MarketMinLiquidityUSD: getEnvFloat("MARKET_MIN_LIQUIDITY_USD", 0),
+		MarketEndMinHours:     getEnvInt("MARKET_END_MIN_HOURS", 0),
+		MarketEndMaxHours:     getEnvInt("MARKET_END_MAX_HOURS", 0),
+		MarketSlugAllowlist:   getEnvList("MARKET_SLUG_ALLOWLIST"),
+		MarketSlugDenylist:    getEnvList("MARKET_SLUG_DENYLIST"),
 
 		// RPC
 		AlchemyAPIKey:  getEnv("ALCHEMY_API_KEY", ""),
 		AlchemyURL:     getEnv("ALCHEMY_URL", "https://polygon-mainnet.g.alchemy.com/v2/"),
 		FallbackRPCURL: getEnv("FALLBACK_RPC_URL", "https://polygon-rpc.com"),
 
+		// Enrichment
+		RPCTimeout:          time.Duration(getEnvInt("RPC_TIMEOUT_SECONDS", 5)) * time.Second,
+		NonceCacheTTL:       time.Duration(getEnvInt("NONCE_CACHE_TTL_SECONDS", 300)) * time.Second,
+		RPCBreakerThreshold: getEnvInt("RPC_BREAKER_FAILURES", 5),
+		RPCBreakerCooldown:  time.Duration(getEnvInt("RPC_BREAKER_COOLDOWN_SECONDS", 30)) * time.Second,
+
+		// Funding
+		FundingTraceEnabled: getEnvBool("FUNDING_TRACE_ENABLED", true),
+		FundingRPCURL:       getEnv("FUNDING_RPC_URL", ""),
+
+		// Wallet age
+		WalletAgeEnabled: getEnvBool("WALLET_AGE_ENABLED", false),
+
 		// Thresholds
-		MinValueUSD:      getEnvFloat("MIN_VALUE_USD", 2000),
-		WhaleValueUSD:    getEnvFloat("WHALE_VALUE_USD", 50000),
-		FreshWalletNonce: getEnvInt("FRESH_WALLET_NONCE", 5),
-		BurstCount:       getEnvInt("BURST_COUNT", 3),
-		BurstWindow:      time.Duration(getEnvInt("BURST_WINDOW_SECONDS", 60)) * time.Second,
+		MinValueUSD:       getEnvFloat("MIN_VALUE_USD", 2000),
+		WhaleValueUSD:     getEnvFloat("WHALE_VALUE_USD", 50000),
+		FreshWalletNonce:  getEnvInt("FRESH_WALLET_NONCE", 5),
+		FreshWalletMaxAge: time.Duration(getEnvFloat("FRESH_WALLET_MAX_AGE_DAYS", 7) * float64(24*time.Hour)),
+		BurstCount:        getEnvInt("BURST_COUNT", 3),
+		BurstWindow:       time.Duration(getEnvInt("BURST_WINDOW_SECONDS", 60)) * time.Second,
+
+		// Price shock
+		PriceShockPct:    getEnvFloat("PRICE_SHOCK_PCT", 0.05),
+		PriceShockPoints: getEnvFloat("PRICE_SHOCK_POINTS", 0.02),
+		PriceShockWindow: time.Duration(getEnvInt("PRICE_SHOCK_WINDOW_SECONDS", 300)) * time.Second,
+		PriceShockMinUSD: getEnvFloat("PRICE_SHOCK_MIN_USD", 500),
+
+		// Relative whale
+		RelativeWhaleMinUSD:            getEnvFloat("RELATIVE_WHALE_MIN_USD", 5000),
+		RelativeWhaleVolumeFraction:    getEnvFloat("RELATIVE_WHALE_VOLUME_FRACTION", 0.01),
+		RelativeWhaleLiquidityFraction: getEnvFloat("RELATIVE_WHALE_LIQUIDITY_FRACTION", 0.10),
+		RelativeWhaleRecentFraction:    getEnvFloat("RELATIVE_WHALE_RECENT_FRACTION", 0.50),
+		RelativeWhalePercentile:        getEnvFloat("RELATIVE_WHALE_PERCENTILE", 0.99),
+		RelativeWhaleMinTrades:         getEnvInt("RELATIVE_WHALE_MIN_TRADES", 30),
+
+		// Accumulation
+		AccumulationWindow:      time.Duration(getEnvInt("ACCUMULATION_WINDOW_HOURS", 24)) * time.Hour,
+		AccumulationMinTradeUSD: getEnvFloat("ACCUMULATION_MIN_TRADE_USD", 500),
+
+		// Fresh swarm
+		FreshSwarmMinWallets:  getEnvInt("FRESH_SWARM_MIN_WALLETS", 5),
+		FreshSwarmWindow:      time.Duration(getEnvInt("FRESH_SWARM_WINDOW_MINUTES", 10)) * time.Minute,
+		FreshSwarmMinTradeUSD: getEnvFloat("FRESH_SWARM_MIN_TRADE_USD", 1000),
+
+		// Wash trades
+		WashWindow:        time.Duration(getEnvInt("WASH_WINDOW_MINUTES", 60)) * time.Minute,
+		WashMinRoundTrips: getEnvInt("WASH_MIN_ROUND_TRIPS", 2),
+
+		// Volume anomaly
+		VolumeAnomalyZ:          getEnvFloat("VOLUME_ANOMALY_Z", 4),
+		VolumeAnomalyAlpha:      getEnvFloat("VOLUME_ANOMALY_ALPHA", 0.05),
+		VolumeAnomalyMinMinutes: getEnvInt("VOLUME_ANOMALY_MIN_MINUTES", 30),
+		VolumeAnomalyMinUSD:     getEnvFloat("VOLUME_ANOMALY_MIN_USD", 1000),
+
+		// Event time
+		EventLateness: time.Duration(getEnvInt("EVENT_LATENESS_SECONDS", 5)) * time.Second,
+
+		// Rules
+		RulesEnabled:  getEnvList("RULES_ENABLED"),
+		RulesDisabled: getEnvList("RULES_DISABLED"),
+		RulesFile:     getEnv("RULES_FILE", ""),
 
 		// Alerting
 		DiscordWebhookURL:  getEnv("DISCORD_WEBHOOK_URL", ""),
@@ -88,6 +271,12 @@ func Load() (*Config, error) {
 		// Workers
 		WorkerCount: getEnvInt("WORKER_COUNT", 5),
 
+		// Event Bus
+		BusBufferSize:   getEnvInt("BUS_BUFFER_SIZE", 1000),
+		BusWorkerPolicy: getEnv("BUS_WORKER_POLICY", "drop_newest"),
+		BusUIPolicy:     getEnv("BUS_UI_POLICY", "drop_oldest"),
+		BusSinkPolicy:   getEnv("BUS_SINK_POLICY", "block"),
+
 		// Metrics
 		PrometheusPort: getEnvInt("PROMETHEUS_PORT", 9090),
 
@@ -108,10 +297,40 @@ func Load() (*Config, error) {
 
 // Validate checks that required configuration values are set and valid.
 func (c *Config) Validate() error {
+	switch c.RunMode {
+	case RunModeLive:
+	case RunModeReplay:
+		if c.ReplayFile == "" {
+			return fmt.Errorf("REPLAY_FILE is required when RUN_MODE=replay")
+		}
+	default:
+		return fmt.Errorf("RUN_MODE must be one of live, replay")
+	}
+
+	if c.ReplaySpeed < 0 {
+		return fmt.Errorf("REPLAY_SPEED must not be negative")
+	}
+
+	if c.CaptureMaxMB < 1 {
+		return fmt.Errorf("CAPTURE_MAX_MB must be at least 1")
+	}
+
 	if c.PolymarketWSURL == "" {
 		return fmt.Errorf("POLYMARKET_WS_URL is required")
 	}
 
+	if c.WSMaxAssetsPerConn < 1 {
+		return fmt.Errorf("WS_MAX_ASSETS_PER_CONN must be at least 1")
+	}
+
+	if c.DedupWindow <= 0 {
+		return fmt.Errorf("DEDUP_WINDOW_SECONDS must be positive")
+	}
+
+	if c.DedupMaxEntries < 1 {
+		return fmt.Errorf("DEDUP_MAX_ENTRIES must be at least 1")
+	}
+
 	if c.MinValueUSD <= 0 {
 		return fmt.Errorf("MIN_VALUE_USD must be positive")
 	}
@@ -124,6 +343,44 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("WORKER_COUNT must be at least 1")
 	}
 
+	if c.GammaPageSize < 1 {
+		return fmt.Errorf("GAMMA_PAGE_SIZE must be at least 1")
+	}
+
+	if c.MarketRefreshInterval <= 0 {
+		return fmt.Errorf("MARKET_REFRESH_INTERVAL_SECONDS must be positive")
+	}
+
+	if c.MarketEndMinHours < 0 || c.MarketEndMaxHours < 0 {
+		return fmt.Errorf("MARKET_END_MIN_HOURS and MARKET_END_MAX_HOURS must not be negative")
+	}
+
+	if c.MarketEndMaxHours > 0 && c.MarketEndMaxHours < c.MarketEndMinHours {
+		return fmt.Errorf("MARKET_END_MAX_HOURS must be at least MARKET_END_MIN_HOURS")
+	}
+
+	if c.EventLateness < 0 {
+		return fmt.Errorf("EVENT_LATENESS_SECONDS must not be negative")
+	}
+
+	if c.RPCBreakerThreshold < 1 {
+		return fmt.Errorf("RPC_BREAKER_FAILURES must be at least 1")
+	}
+
+	if c.BusBufferSize < 1 {
+		return fmt.Errorf("BUS_BUFFER_SIZE must be at least 1")
+	}
+
+	for key, policy := range map[string]string{
+		"BUS_WORKER_POLICY": c.BusWorkerPolicy,
+		"BUS_UI_POLICY":     c.BusUIPolicy,
+		"BUS_SINK_POLICY":   c.BusSinkPolicy,
+	} {
+		if _, err := bus.ParsePolicy(policy); err != nil {
+			return fmt.Errorf("%s must be one of block, drop_newest, drop_oldest", key)
+		}
+	}
+
 	if c.PrometheusPort < 1 || c.PrometheusPort > 65535 {
 		return fmt.Errorf("PROMETHEUS_PORT must be between 1 and 65535")
 	}
@@ -180,6 +437,18 @@ func getEnvFloat(key string, defaultValue float64) float64 {
 	return defaultValue
 }
 
+// getEnvList retrieves a comma-separated environment variable as a list.
+// Empty entries are dropped; an unset variable yields nil.
+func getEnvList(key string) []string {
+	var list []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
 // getEnvBool retrieves an environment variable as a boolean or returns a default.
 func getEnvBool(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
@@ -0,0 +1,141 @@
+package detector
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/polyinsider/engine/internal/clock"
+	"github.com/polyinsider/engine/internal/store"
+)
+
+// ledgerEntry is one trade's signed contribution to a position:
+// positive for buys, negative for sells.
+type ledgerEntry struct {
+	at       time.Time
+	notional float64
+	tradeID  string
+}
+
+// position is a wallet's rolling exposure to one asset.
+type position struct {
+	entries []ledgerEntry // sorted by event time
+	fired   bool          // the threshold was reported and not since dropped below
+}
+
+// net returns the signed sum of the position's entries.
+func (p *position) net() float64 {
+	total := 0.0
+	for _, entry := range p.entries {
+		total += entry.notional
+	}
+	return total
+}
+
+// accumulationRule flags a wallet whose net buying or selling of one asset
+// within the window crosses threshold, as split orders do when each stays
+// under the whale size. It fires once per crossing and needs at least two
+// trades, leaving single large trades to WHALE.
+type accumulationRule struct {
+	threshold float64
+	window    time.Duration
+	minTrade  float64 // trades below this are not tracked
+
+	mu        sync.Mutex
+	positions map[string]*position // maker|asset -> position
+	watermark *clock.Watermark
+}
+
+func newAccumulationRule(threshold float64, window time.Duration, minTrade float64, lateness time.Duration) *accumulationRule {
+	return &accumulationRule{
+		threshold: threshold,
+		window:    window,
+		minTrade:  minTrade,
+		positions: make(map[string]*position),
+		watermark: clock.NewWatermark(lateness),
+	}
+}
+
+func (r *accumulationRule) Name() string     { return store.SignalAccumulation }
+func (r *accumulationRule) Severity() string { return store.SeverityHigh }
+
+func (r *accumulationRule) Evaluate(e Event) []store.Suspect {
+	trade := e.Trade
+	if r.threshold <= 0 || trade.MakerAddress == "" || trade.AssetID == "" || trade.ValueUSD < r.minTrade {
+		return nil
+	}
+
+	buy, ok := e.Buying()
+	if !ok {
+		return nil
+	}
+	sign := -1.0
+	if buy {
+		sign = 1
+	}
+
+	r.watermark.Observe(e.Time)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := strings.ToLower(trade.MakerAddress) + "|" + trade.AssetID
+	pos, exists := r.positions[key]
+	if !exists {
+		pos = &position{}
+		r.positions[key] = pos
+	}
+
+	cutoff := r.watermark.Current().Add(-r.window)
+	first := sort.Search(len(pos.entries), func(i int) bool { return !pos.entries[i].at.Before(cutoff) })
+	pos.entries = pos.entries[first:]
+
+	i := sort.Search(len(pos.entries), func(i int) bool { return pos.entries[i].at.After(e.Time) })
+	pos.entries = append(pos.entries, ledgerEntry{})
+	copy(pos.entries[i+1:], pos.entries[i:])
+	pos.entries[i] = ledgerEntry{at: e.Time, notional: sign * trade.ValueUSD, tradeID: e.TradeID()}
+
+	net := pos.net()
+	if math.Abs(net) < r.threshold {
+		pos.fired = false
+		return nil
+	}
+	if pos.fired || len(pos.entries) < 2 {
+		return nil
+	}
+	pos.fired = true
+
+	tradeIDs := make([]string, 0, len(pos.entries))
+	for _, entry := range pos.entries {
+		tradeIDs = append(tradeIDs, entry.tradeID)
+	}
+
+	direction := "BUY"
+	if net < 0 {
+		direction = "SELL"
+	}
+
+	return []store.Suspect{e.Suspect(map[string]interface{}{
+		"net_usd":      net,
+		"direction":    direction,
+		"trades":       len(pos.entries),
+		"trade_ids":    tradeIDs,
+		"first_trade":  pos.entries[0].at,
+		"window_hours": r.window.Hours(),
+	})}
+}
+
+// Cleanup drops positions with no trades in the window.
+func (r *accumulationRule) Cleanup() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cutoff := r.watermark.Current().Add(-r.window)
+	for key, pos := range r.positions {
+		if len(pos.entries) == 0 || pos.entries[len(pos.entries)-1].at.Before(cutoff) {
+			delete(r.positions, key)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package detector
+
+import (
+	"time"
+
+	"github.com/polyinsider/engine/internal/config"
+	"github.com/polyinsider/engine/internal/store"
+)
+
+// DefaultRules returns the built-in rules in evaluation order.
+func DefaultRules(cfg *config.Config) []Rule {
+	return []Rule{
+		newPriceShockRule(cfg.PriceShockPct, cfg.PriceShockPoints, cfg.PriceShockWindow, cfg.EventLateness, cfg.PriceShockMinUSD),
+		&whaleRule{minValue: cfg.WhaleValueUSD},
+		&relativeWhaleRule{
+			minValue:          cfg.RelativeWhaleMinUSD,
+			volumeFraction:    cfg.RelativeWhaleVolumeFraction,
+			liquidityFraction: cfg.RelativeWhaleLiquidityFraction,
+			recentFraction:    cfg.RelativeWhaleRecentFraction,
+			percentile:        cfg.RelativeWhalePercentile,
+			minTrades:         cfg.RelativeWhaleMinTrades,
+		},
+		newAccumulationRule(cfg.WhaleValueUSD, cfg.AccumulationWindow, cfg.AccumulationMinTradeUSD, cfg.EventLateness),
+		newVolumeAnomalyRule(cfg.VolumeAnomalyZ, cfg.VolumeAnomalyAlpha, cfg.VolumeAnomalyMinMinutes,
+			cfg.VolumeAnomalyMinUSD, cfg.EventLateness),
+		newWashTradeRule(cfg.WashWindow, cfg.WashMinRoundTrips, cfg.EventLateness),
+		&freshInsiderRule{minValue: cfg.MinValueUSD, maxNonce: cfg.FreshWalletNonce, maxAge: cfg.FreshWalletMaxAge},
+		newFreshSwarmRule(cfg.FreshSwarmMinWallets, cfg.FreshWalletNonce, cfg.FreshSwarmMinTradeUSD,
+			cfg.FreshSwarmWindow, cfg.EventLateness),
+		&burstRule{tracker: NewBurstTracker(cfg.BurstWindow, cfg.EventLateness), count: cfg.BurstCount},
+	}
+}
+
+// whaleRule flags trades at or above the whale value.
+type whaleRule struct {
+	minValue float64
+}
+
+func (r *whaleRule) Name() string     { return store.SignalWhale }
+func (r *whaleRule) Severity() string { return store.SeverityMedium }
+
+func (r *whaleRule) Evaluate(e Event) []store.Suspect {
+	if e.Trade.ValueUSD < r.minValue {
+		return nil
+	}
+	return []store.Suspect{e.Suspect(nil)}
+}
+
+// relativeWhaleRule flags trades that are large for their own market: a
+// fraction of its lifetime volume or liquidity, a fraction of its traded
+// volume in the tracker window, or above a percentile of its recent trades.
+// Each check is skipped when its threshold is 0 or the market data is unknown.
+type relativeWhaleRule struct {
+	minValue          float64
+	volumeFraction    float64
+	liquidityFraction float64
+	recentFraction    float64
+	percentile        float64
+	minTrades         int
+}
+
+func (r *relativeWhaleRule) Name() string     { return store.SignalRelativeWhale }
+func (r *relativeWhaleRule) Severity() string { return store.SeverityMedium }
+
+func (r *relativeWhaleRule) Evaluate(e Event) []store.Suspect {
+	value := e.Trade.ValueUSD
+	if value <= 0 || value < r.minValue {
+		return nil
+	}
+
+	meta := make(map[string]interface{})
+	var triggers []string
+
+	check := func(name string, ratio, threshold float64) {
+		meta[name] = ratio
+		if threshold > 0 && ratio >= threshold {
+			triggers = append(triggers, name)
+		}
+	}
+
+	if e.MarketVolume > 0 {
+		check("volume_ratio", value/e.MarketVolume, r.volumeFraction)
+	}
+	if e.MarketLiquidity > 0 {
+		check("liquidity_ratio", value/e.MarketLiquidity, r.liquidityFraction)
+	}
+	if e.RecentTrades >= r.minTrades && e.RecentVolume > 0 {
+		check("recent_ratio", value/(e.RecentVolume+value), r.recentFraction)
+		check("percentile", e.ValueRank, r.percentile)
+	}
+
+	if len(triggers) == 0 {
+		return nil
+	}
+	meta["triggers"] = triggers
+	return []store.Suspect{e.Suspect(meta)}
+}
+
+// freshInsiderRule flags sizeable trades from wallets with few transactions.
+// It only fires when the nonce is known. With maxAge set, a wallet first
+// active longer ago is not fresh whatever its nonce; an unknown age is
+// judged by the nonce alone.
+type freshInsiderRule struct {
+	minValue float64
+	maxNonce int
+	maxAge   time.Duration
+}
+
+func (r *freshInsiderRule) Name() string     { return store.SignalFreshInsider }
+func (r *freshInsiderRule) Severity() string { return store.SeverityHigh }
+
+// WantsNonce limits RPC lookups to trades that could qualify.
+func (r *freshInsiderRule) WantsNonce(trade store.Trade) bool {
+	return trade.ValueUSD >= r.minValue && trade.MakerAddress != ""
+}
+
+func (r *freshInsiderRule) Evaluate(e Event) []store.Suspect {
+	if e.Nonce < 0 || e.Trade.ValueUSD < r.minValue || e.Nonce > r.maxNonce {
+		return nil
+	}
+	if e.WalletAge <= 0 {
+		return []store.Suspect{e.Suspect(nil)}
+	}
+	if r.maxAge > 0 && e.WalletAge > r.maxAge {
+		return nil
+	}
+	return []store.Suspect{e.Suspect(map[string]interface{}{"wallet_age_days": e.WalletAge.Hours() / 24})}
+}
+
+// burstRule flags a maker trading count or more times within the burst window.
+type burstRule struct {
+	tracker *BurstTracker
+	count   int
+}
+
+func (r *burstRule) Name() string     { return store.SignalPanicBurst }
+func (r *burstRule) Severity() string { return store.SeverityMedium }
+
+func (r *burstRule) Evaluate(e Event) []store.Suspect {
+	if e.Trade.MakerAddress == "" {
+		return nil
+	}
+	if r.tracker.Record(e.Trade.MakerAddress, e.Time) < r.count {
+		return nil
+	}
+	return []store.Suspect{e.Suspect(nil)}
+}
+
+// Cleanup drops makers with no trades in the window.
+func (r *burstRule) Cleanup() {
+	r.tracker.Cleanup()
+}
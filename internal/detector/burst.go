@@ -1,60 +1,62 @@
 package detector
 
 import (
+	"sort"
 	"sync"
 	"time"
+
+	"github.com/polyinsider/engine/internal/clock"
 )
 
 // BurstTracker tracks trade frequency per address to detect panic bursts.
+// Windows are measured in event time, so replayed or delayed trades are
+// counted against the trades that happened around them, not around arrival.
 type BurstTracker struct {
-	mu     sync.RWMutex
-	trades map[string][]time.Time
-	window time.Duration
+	mu        sync.RWMutex
+	trades    map[string][]time.Time // address -> event times, sorted
+	window    time.Duration
+	watermark *clock.Watermark
 }
 
 // NewBurstTracker creates a new BurstTracker with the specified window.
-func NewBurstTracker(window time.Duration) *BurstTracker {
+// Trades may arrive up to lateness out of order and still be counted exactly.
+func NewBurstTracker(window, lateness time.Duration) *BurstTracker {
 	return &BurstTracker{
-		trades: make(map[string][]time.Time),
-		window: window,
+		trades:    make(map[string][]time.Time),
+		window:    window,
+		watermark: clock.NewWatermark(lateness),
 	}
 }
 
-// Record adds a trade for the given address and returns the number of trades
-// within the window (including the new one).
-func (b *BurstTracker) Record(address string) int {
+// Record adds a trade made by address at the given event time and returns
+// the largest number of that address's trades in one window containing it
+// (including the new one). For in-order trades this is the window ending at it.
+func (b *BurstTracker) Record(address string, at time.Time) int {
+	b.watermark.Observe(at)
+
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	now := time.Now()
-	cutoff := now.Add(-b.window)
-
-	// Get existing timestamps
-	timestamps := b.trades[address]
-	
-	// Filter out old timestamps
-	validIdx := 0
-	for i, t := range timestamps {
-		if t.After(cutoff) {
-			validIdx = i
-			break
-		}
-		// If we reach the end and none are valid
-		if i == len(timestamps)-1 && !t.After(cutoff) {
-			validIdx = len(timestamps)
-		}
-	}
-
-	// Slice off old ones
-	if validIdx > 0 {
-		timestamps = timestamps[validIdx:]
-	}
+	// Drop timestamps no on-time trade can reach back to
+	timestamps := evictBefore(b.trades[address], b.watermark.Current().Add(-b.window))
 
-	// Add new timestamp
-	timestamps = append(timestamps, now)
+	// Insert in event-time order
+	i := sort.Search(len(timestamps), func(i int) bool { return timestamps[i].After(at) })
+	timestamps = append(timestamps, time.Time{})
+	copy(timestamps[i+1:], timestamps[i:])
+	timestamps[i] = at
 	b.trades[address] = timestamps
 
-	return len(timestamps)
+	// An out-of-order trade may complete a burst with trades after it, so
+	// take the busiest window ending at this trade or at any later one
+	// that still contains it
+	best := 0
+	for end := i; end < len(timestamps) && timestamps[end].Sub(at) < b.window; end++ {
+		cutoff := timestamps[end].Add(-b.window)
+		first := sort.Search(len(timestamps), func(j int) bool { return timestamps[j].After(cutoff) })
+		best = max(best, end+1-first)
+	}
+	return best
 }
 
 // Cleanup removes entries for addresses with no recent trades.
@@ -63,14 +65,18 @@ func (b *BurstTracker) Cleanup() {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	now := time.Now()
-	cutoff := now.Add(-b.window)
+	cutoff := b.watermark.Current().Add(-b.window)
 
 	for addr, timestamps := range b.trades {
 		// Check last timestamp (most recent)
-		if len(timestamps) == 0 || !timestamps[len(timestamps)-1].After(cutoff) {
+		if len(timestamps) == 0 || timestamps[len(timestamps)-1].Before(cutoff) {
 			delete(b.trades, addr)
 		}
 	}
 }
 
+// evictBefore drops the leading timestamps older than cutoff.
+func evictBefore(timestamps []time.Time, cutoff time.Time) []time.Time {
+	i := sort.Search(len(timestamps), func(i int) bool { return !timestamps[i].Before(cutoff) })
+	return timestamps[i:]
+}
@@ -1,9 +1,14 @@
 package detector
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
 
+	"github.com/polyinsider/engine/internal/clock"
 	"github.com/polyinsider/engine/internal/config"
 	"github.com/polyinsider/engine/internal/store"
 )
@@ -17,7 +22,7 @@ func TestDetector(t *testing.T) {
 		BurstWindow:      60 * time.Second,
 	}
 
-	d := NewDetector(cfg)
+	d := NewDetector(cfg, clock.NewManual(time.Unix(1_700_000_000, 0)))
 
 	// Test Case 1: Whale
 	whaleTrade := store.Trade{
@@ -25,7 +30,7 @@ func TestDetector(t *testing.T) {
 		ValueUSD:     55000,
 		MakerAddress: "0xWhale",
 	}
-	signals := d.Detect(whaleTrade, -1)
+	signals := d.Detect(whaleTrade, Enrichment{Nonce: -1})
 	if len(signals) != 1 || signals[0].SignalType != store.SignalWhale {
 		t.Errorf("Expected 1 Whale signal, got %v", signals)
 	}
@@ -36,7 +41,7 @@ func TestDetector(t *testing.T) {
 		ValueUSD:     5000, // > 2000
 		MakerAddress: "0xFresh",
 	}
-	signals = d.Detect(freshTrade, 2) // Nonce 2 < 5
+	signals = d.Detect(freshTrade, Enrichment{Nonce: 2}) // Nonce 2 < 5
 	if len(signals) != 1 || signals[0].SignalType != store.SignalFreshInsider {
 		t.Errorf("Expected 1 Fresh Insider signal, got %v", signals)
 	}
@@ -50,7 +55,7 @@ func TestDetector(t *testing.T) {
 		ValueUSD:     1000, // < 2000
 		MakerAddress: "0xSmall",
 	}
-	signals = d.Detect(smallFreshTrade, 2)
+	signals = d.Detect(smallFreshTrade, Enrichment{Nonce: 2})
 	if len(signals) != 0 {
 		t.Errorf("Expected 0 signals for small trade, got %v", signals)
 	}
@@ -64,19 +69,19 @@ func TestDetector(t *testing.T) {
 	}
 
 	// First trade
-	signals = d.Detect(burstTrade, -1)
+	signals = d.Detect(burstTrade, Enrichment{Nonce: -1})
 	if len(signals) != 0 {
 		t.Errorf("Expected 0 signals on first burst trade")
 	}
 
 	// Second trade
-	signals = d.Detect(burstTrade, -1)
+	signals = d.Detect(burstTrade, Enrichment{Nonce: -1})
 	if len(signals) != 0 {
 		t.Errorf("Expected 0 signals on second burst trade")
 	}
 
 	// Third trade (should trigger)
-	signals = d.Detect(burstTrade, -1)
+	signals = d.Detect(burstTrade, Enrichment{Nonce: -1})
 	if len(signals) != 1 || signals[0].SignalType != store.SignalPanicBurst {
 		t.Errorf("Expected Panic Burst signal on third trade, got %v", signals)
 	}
@@ -86,7 +91,7 @@ func TestShouldEnrich(t *testing.T) {
 	cfg := &config.Config{
 		MinValueUSD: 2000,
 	}
-	d := NewDetector(cfg)
+	d := NewDetector(cfg, clock.Real{})
 
 	// Should enrich high value with address
 	trade := store.Trade{
@@ -116,3 +121,467 @@ func TestShouldEnrich(t *testing.T) {
 	}
 }
 
+func TestBurstUsesEventTime(t *testing.T) {
+	cfg := &config.Config{
+		MinValueUSD:   2000,
+		WhaleValueUSD: 50000,
+		BurstCount:    3,
+		BurstWindow:   60 * time.Second,
+		EventLateness: 10 * time.Second,
+	}
+	start := time.Unix(1_700_000_000, 0)
+	d := NewDetector(cfg, clock.NewManual(start))
+
+	trade := func(offset time.Duration) store.Trade {
+		return store.Trade{ValueUSD: 100, MakerAddress: "0xBurst", Timestamp: start.Add(offset)}
+	}
+
+	// Replayed trades two minutes apart in event time are not a burst,
+	// however quickly they are processed
+	for _, offset := range []time.Duration{0, 2 * time.Minute, 4 * time.Minute} {
+		if signals := d.Detect(trade(offset), Enrichment{Nonce: -1}); len(signals) != 0 {
+			t.Fatalf("Expected no burst for spread-out trades, got %v", signals)
+		}
+	}
+
+	// A trade arriving late but within the lateness still completes a burst
+	d.Detect(trade(4*time.Minute+20*time.Second), Enrichment{Nonce: -1})
+	signals := d.Detect(trade(4*time.Minute+10*time.Second), Enrichment{Nonce: -1})
+	if len(signals) != 1 || signals[0].SignalType != store.SignalPanicBurst {
+		t.Errorf("Expected out-of-order trade to complete a burst, got %v", signals)
+	}
+}
+
+type recordingRule struct {
+	events []Event
+}
+
+func (r *recordingRule) Name() string     { return "CUSTOM" }
+func (r *recordingRule) Severity() string { return store.SeverityCritical }
+
+func (r *recordingRule) Evaluate(e Event) []store.Suspect {
+	r.events = append(r.events, e)
+	return []store.Suspect{e.Suspect(nil)}
+}
+
+func TestRulesConfigAndRegistration(t *testing.T) {
+	cfg := &config.Config{
+		MinValueUSD:   2000,
+		WhaleValueUSD: 50000,
+		BurstCount:    3,
+		BurstWindow:   time.Minute,
+		RulesDisabled: []string{"whale", store.SignalRelativeWhale, store.SignalAccumulation, store.SignalVolumeAnomaly, store.SignalWashTrade, store.SignalFreshSwarm, store.SignalPanicBurst},
+	}
+	d := NewDetector(cfg, clock.Real{})
+
+	if got := d.Rules(); len(got) != 2 || got[0] != store.SignalPriceShock || got[1] != store.SignalFreshInsider {
+		t.Fatalf("Expected price shock and fresh insider rules, got %v", got)
+	}
+	if signals := d.Detect(store.Trade{ValueUSD: 100000, MakerAddress: "0xWhale"}, Enrichment{Nonce: -1}); len(signals) != 0 {
+		t.Errorf("Expected disabled whale rule to stay silent, got %v", signals)
+	}
+
+	custom := &recordingRule{}
+	if err := d.Register(custom); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Register(custom); err == nil {
+		t.Error("Expected duplicate rule name to be rejected")
+	}
+
+	signals := d.Detect(store.Trade{ValueUSD: 10, MakerAddress: "0xA"}, Enrichment{Nonce: 7})
+	if len(signals) != 1 || signals[0].SignalType != "CUSTOM" || signals[0].Severity != store.SeverityCritical || signals[0].Nonce != 7 {
+		t.Errorf("Expected custom rule suspect with defaults filled in, got %+v", signals)
+	}
+	if len(custom.events) != 1 || custom.events[0].Time.IsZero() {
+		t.Errorf("Expected custom rule to see the event with its time, got %+v", custom.events)
+	}
+
+	// An enabled list keeps only the named rules
+	only := NewDetector(&config.Config{RulesEnabled: []string{store.SignalWhale}, WhaleValueUSD: 1}, clock.Real{})
+	if got := only.Rules(); len(got) != 1 || got[0] != store.SignalWhale {
+		t.Errorf("Expected only the whale rule, got %v", got)
+	}
+}
+
+func TestPriceShockWindow(t *testing.T) {
+	cfg := &config.Config{
+		RulesEnabled:     []string{store.SignalPriceShock},
+		PriceShockPct:    0.05,
+		PriceShockPoints: 0.02,
+		PriceShockWindow: 5 * time.Minute,
+		PriceShockMinUSD: 500,
+	}
+	start := time.Unix(1_700_000_000, 0)
+	d := NewDetector(cfg, clock.NewManual(start))
+
+	trade := func(offset time.Duration, price, value float64, maker string) store.Trade {
+		return store.Trade{AssetID: "tok", Price: price, ValueUSD: value, MakerAddress: maker, Timestamp: start.Add(offset)}
+	}
+
+	// +100% but only one probability point
+	d.Detect(trade(0, 0.01, 1000, "0xA"), Enrichment{Nonce: -1})
+	if signals := d.Detect(trade(time.Second, 0.02, 1000, "0xA"), Enrichment{Nonce: -1}); len(signals) != 0 {
+		t.Errorf("Expected no shock for a one-point move, got %+v", signals)
+	}
+
+	// A climb in small steps is one move within the window
+	d.Detect(trade(time.Minute, 0.40, 1000, "0xB"), Enrichment{Nonce: -1})
+	d.Detect(trade(2*time.Minute, 0.41, 1000, "0xB"), Enrichment{Nonce: -1})
+	signals := d.Detect(trade(3*time.Minute, 0.43, 1000, "0xC"), Enrichment{Nonce: -1})
+	if len(signals) != 1 || signals[0].Trade.MakerAddress != "0xC" {
+		t.Fatalf("Expected a windowed shock, got %+v", signals)
+	}
+	if points, _ := signals[0].Meta["point_change"].(float64); points < 0.0299 {
+		t.Errorf("Expected point change against the window extreme, got %v", signals[0].Meta)
+	}
+
+	// Zero-value prints never shock, but a wallet at that price gets the credit
+	if signals := d.Detect(trade(3*time.Minute+time.Second, 0.50, 0, ""), Enrichment{Nonce: -1}); len(signals) != 0 {
+		t.Errorf("Expected zero-value print to be ignored, got %+v", signals)
+	}
+	d.Detect(trade(3*time.Minute+2*time.Second, 0.55, 200, "0xD"), Enrichment{Nonce: -1})
+	signals = d.Detect(trade(3*time.Minute+3*time.Second, 0.55, 800, ""), Enrichment{Nonce: -1})
+	if len(signals) != 1 || signals[0].Trade.MakerAddress != "0xD" || signals[0].Meta["attributed"] != true {
+		t.Errorf("Expected anonymous shock attributed to 0xD, got %+v", signals)
+	}
+
+	// The same move is not reported twice
+	if signals := d.Detect(trade(3*time.Minute+4*time.Second, 0.55, 800, "0xE"), Enrichment{Nonce: -1}); len(signals) != 0 {
+		t.Errorf("Expected repeated move to be suppressed, got %+v", signals)
+	}
+}
+
+func TestAccumulation(t *testing.T) {
+	cfg := &config.Config{
+		RulesEnabled:            []string{store.SignalAccumulation},
+		WhaleValueUSD:           50000,
+		AccumulationWindow:      24 * time.Hour,
+		AccumulationMinTradeUSD: 500,
+	}
+	start := time.Unix(1_700_000_000, 0)
+	d := NewDetector(cfg, clock.NewManual(start))
+
+	buy := func(id string, at time.Duration, value float64) store.Trade {
+		return store.Trade{ID: id, AssetID: "tok", Side: "BUY", ValueUSD: value, MakerAddress: "0xSplit", Timestamp: start.Add(at)}
+	}
+
+	// A sell in between reduces the net position
+	d.Detect(store.Trade{ID: "s", AssetID: "tok", Side: "SELL", ValueUSD: 5000, MakerAddress: "0xSplit", Timestamp: start}, Enrichment{Nonce: -1})
+
+	// Twelve 5k buys an hour apart: no burst, no whale, but 55k net after the last
+	var fired []store.Suspect
+	for i := 0; i < 12; i++ {
+		fired = append(fired, d.Detect(buy(fmt.Sprintf("b%d", i), time.Duration(i+1)*time.Hour, 5000), Enrichment{Nonce: -1})...)
+	}
+	if len(fired) != 1 || fired[0].SignalType != store.SignalAccumulation || fired[0].Trade.ID != "b10" {
+		t.Fatalf("Expected accumulation on the trade crossing 50k net, got %+v", fired)
+	}
+	ids, _ := fired[0].Meta["trade_ids"].([]string)
+	if len(ids) != 12 || ids[0] != "s" || ids[11] != "b10" {
+		t.Errorf("Expected contributing trade IDs, got %v", fired[0].Meta["trade_ids"])
+	}
+
+	// Trades outside the window no longer count
+	if signals := d.Detect(buy("late", 40*time.Hour, 5000), Enrichment{Nonce: -1}); len(signals) != 0 {
+		t.Errorf("Expected old trades to age out, got %+v", signals)
+	}
+}
+
+func TestFreshSwarm(t *testing.T) {
+	cfg := &config.Config{
+		RulesEnabled:          []string{store.SignalFreshSwarm},
+		FreshWalletNonce:      5,
+		FreshSwarmMinWallets:  3,
+		FreshSwarmWindow:      10 * time.Minute,
+		FreshSwarmMinTradeUSD: 1000,
+	}
+	start := time.Unix(1_700_000_000, 0)
+	d := NewDetector(cfg, clock.NewManual(start))
+
+	buy := func(wallet string, at time.Duration, value float64) store.Trade {
+		return store.Trade{AssetID: "yes", Side: "BUY", ValueUSD: value, MakerAddress: wallet, Timestamp: start.Add(at)}
+	}
+	if !d.ShouldEnrich(buy("0x1", 0, 1500)) || d.ShouldEnrich(buy("0x1", 0, 500)) {
+		t.Error("Expected nonces requested only for trades that can join a swarm")
+	}
+
+	d.Detect(buy("0x1", 0, 2000), Enrichment{Nonce: 1})
+	d.Detect(buy("0x1", time.Minute, 2000), Enrichment{Nonce: 1})    // same wallet again
+	d.Detect(buy("0x2", 2*time.Minute, 3000), Enrichment{Nonce: 50}) // not fresh
+	d.Detect(buy("0x3", 3*time.Minute, 3000), Enrichment{Nonce: -1}) // nonce unknown
+	d.Detect(store.Trade{AssetID: "yes", Side: "SELL", ValueUSD: 3000, MakerAddress: "0x4", Timestamp: start.Add(4 * time.Minute)}, Enrichment{Nonce: 0})
+	d.Detect(buy("0x5", 5*time.Minute, 1500), Enrichment{Nonce: 2})
+
+	signals := d.Detect(buy("0x6", 6*time.Minute, 4000), Enrichment{Nonce: 0})
+	if len(signals) != 1 || signals[0].SignalType != store.SignalFreshSwarm {
+		t.Fatalf("Expected a fresh swarm on the third fresh buyer, got %+v", signals)
+	}
+	wallets, _ := signals[0].Meta["wallets"].([]string)
+	if len(wallets) != 3 || signals[0].Meta["combined_usd"] != 9500.0 {
+		t.Errorf("Expected three wallets and 9500 combined, got %+v", signals[0].Meta)
+	}
+
+	// The swarm is reported once while it lasts
+	if signals := d.Detect(buy("0x7", 7*time.Minute, 1000), Enrichment{Nonce: 0}); len(signals) != 0 {
+		t.Errorf("Expected an ongoing swarm not to be re-reported, got %+v", signals)
+	}
+}
+
+func TestWashTrade(t *testing.T) {
+	cfg := &config.Config{
+		RulesEnabled:      []string{store.SignalWashTrade},
+		WashWindow:        time.Hour,
+		WashMinRoundTrips: 2,
+	}
+	start := time.Unix(1_700_000_000, 0)
+	d := NewDetector(cfg, clock.NewManual(start))
+
+	self := store.Trade{AssetID: "tok", Side: "BUY", MakerAddress: "0xAB", TakerAddress: "0xab", Timestamp: start}
+	if signals := d.Detect(self, Enrichment{Nonce: -1}); len(signals) != 1 || signals[0].Meta["pattern"] != WashSelfMatch {
+		t.Fatalf("Expected self-match wash trade, got %+v", signals)
+	}
+
+	trade := func(id string, at time.Duration, maker, taker, side string) store.Trade {
+		return store.Trade{ID: id, AssetID: "tok", Side: side, MakerAddress: maker, TakerAddress: taker, Timestamp: start.Add(at)}
+	}
+
+	// A buys from B, B buys from A (taker side flips with the roles), A buys again
+	sequence := []store.Trade{
+		trade("t1", time.Minute, "0xB", "0xA", "BUY"),
+		trade("t2", 2*time.Minute, "0xA", "0xB", "BUY"),
+		trade("t3", 3*time.Minute, "0xB", "0xA", "BUY"),
+	}
+	var fired []store.Suspect
+	var washed []bool
+	for _, tr := range sequence {
+		fired = append(fired, d.Detect(tr, Enrichment{Nonce: -1})...)
+		washed = append(washed, d.IsWash(tr))
+	}
+	if len(fired) != 1 || fired[0].Meta["pattern"] != WashPingPong || fired[0].Meta["round_trips"] != 2 {
+		t.Fatalf("Expected one ping-pong wash trade after two round trips, got %+v", fired)
+	}
+
+	// Later trades in the pair raise nothing but stay out of market activity
+	later := trade("t4", 4*time.Minute, "0xA", "0xB", "BUY")
+	if signals := d.Detect(later, Enrichment{Nonce: -1}); len(signals) != 0 {
+		t.Errorf("Expected no repeat suspect, got %+v", signals)
+	}
+	washed = append(washed, d.IsWash(later))
+	if want := []bool{false, false, true, true}; !reflect.DeepEqual(washed, want) {
+		t.Errorf("Expected wash flags %v, got %v", want, washed)
+	}
+	if !d.IsWash(self) {
+		t.Error("Expected a self-match to be a wash trade")
+	}
+
+	// The same direction twice is not a round trip
+	d.Detect(trade("u1", time.Minute, "0xC", "0xD", "BUY"), Enrichment{Nonce: -1})
+	d.Detect(trade("u2", 2*time.Minute, "0xC", "0xD", "BUY"), Enrichment{Nonce: -1})
+	if signals := d.Detect(trade("u3", 3*time.Minute, "0xE", "0xD", "SELL"), Enrichment{Nonce: -1}); len(signals) != 0 {
+		t.Errorf("Expected one-directional trading to pass, got %+v", signals)
+	}
+}
+
+func TestVolumeAnomaly(t *testing.T) {
+	cfg := &config.Config{
+		RulesEnabled:            []string{store.SignalVolumeAnomaly},
+		VolumeAnomalyZ:          4,
+		VolumeAnomalyAlpha:      0.1,
+		VolumeAnomalyMinMinutes: 30,
+		VolumeAnomalyMinUSD:     1000,
+	}
+	start := time.Unix(1_700_000_000, 0).Truncate(time.Minute)
+	d := NewDetector(cfg, clock.NewManual(start))
+
+	trade := func(at time.Duration, value float64) store.Trade {
+		return store.Trade{MarketID: "m1", ValueUSD: value, Timestamp: start.Add(at)}
+	}
+
+	// An hour of steady ~500/min builds the baseline
+	for minute := 0; minute < 60; minute++ {
+		value := 450.0 + float64(minute%3)*50
+		if signals := d.Detect(trade(time.Duration(minute)*time.Minute, value), Enrichment{Nonce: -1}); len(signals) != 0 {
+			t.Fatalf("Expected no anomaly while warming up, got %+v", signals)
+		}
+	}
+
+	// The market wakes up: 6000 in one minute, reported once
+	var fired []store.Suspect
+	for i := 0; i < 6; i++ {
+		fired = append(fired, d.Detect(trade(60*time.Minute+time.Duration(i)*time.Second, 1000), Enrichment{Nonce: -1})...)
+	}
+	if len(fired) != 1 || fired[0].SignalType != store.SignalVolumeAnomaly {
+		t.Fatalf("Expected one volume anomaly, got %+v", fired)
+	}
+	multiple, _ := fired[0].Meta["volume_multiple"].(float64)
+	if multiple < 1.5 || fired[0].Meta["volume_z"].(float64) < 4 {
+		t.Errorf("Expected baseline, multiple and z-score in meta, got %+v", fired[0].Meta)
+	}
+}
+
+func TestVolumeAnomalySkipsWashTrades(t *testing.T) {
+	cfg := &config.Config{
+		RulesEnabled:            []string{store.SignalVolumeAnomaly, store.SignalWashTrade},
+		VolumeAnomalyZ:          4,
+		VolumeAnomalyAlpha:      0.1,
+		VolumeAnomalyMinMinutes: 30,
+		VolumeAnomalyMinUSD:     1000,
+		WashWindow:              time.Hour,
+		WashMinRoundTrips:       2,
+	}
+	start := time.Unix(1_700_000_000, 0).Truncate(time.Minute)
+	d := NewDetector(cfg, clock.NewManual(start))
+
+	for minute := 0; minute < 60; minute++ {
+		trade := store.Trade{MarketID: "m1", ValueUSD: 500, Timestamp: start.Add(time.Duration(minute) * time.Minute)}
+		d.Detect(trade, Enrichment{Nonce: -1})
+	}
+
+	// 6000 of self-matched volume is fake and does not wake the market up
+	for i := 0; i < 6; i++ {
+		self := store.Trade{
+			MarketID: "m1", AssetID: "tok", Side: "BUY", MakerAddress: "0xab", TakerAddress: "0xAB",
+			ValueUSD: 1000, Timestamp: start.Add(60*time.Minute + time.Duration(i)*time.Second),
+		}
+		for _, s := range d.Detect(self, Enrichment{Nonce: -1}) {
+			if s.SignalType == store.SignalVolumeAnomaly {
+				t.Fatalf("Expected wash trades kept out of the volume baseline, got %+v", s)
+			}
+		}
+	}
+}
+
+func TestRelativeWhale(t *testing.T) {
+	cfg := &config.Config{
+		RulesEnabled:                   []string{store.SignalRelativeWhale},
+		RelativeWhaleMinUSD:            1000,
+		RelativeWhaleVolumeFraction:    0.05,
+		RelativeWhaleLiquidityFraction: 0.5,
+		RelativeWhalePercentile:        0.99,
+		RelativeWhaleMinTrades:         10,
+	}
+	d := NewDetector(cfg, clock.Real{})
+	trade := store.Trade{ValueUSD: 10000, MakerAddress: "0xA"}
+
+	// 10k is noise in a market that has traded 50M
+	if signals := d.Detect(trade, Enrichment{Nonce: -1, MarketVolume: 50_000_000, MarketLiquidity: 2_000_000}); len(signals) != 0 {
+		t.Errorf("Expected no signal in a deep market, got %+v", signals)
+	}
+
+	// and a tenth of a niche market's lifetime volume
+	signals := d.Detect(trade, Enrichment{Nonce: -1, MarketVolume: 100_000, MarketLiquidity: 50_000})
+	if len(signals) != 1 || signals[0].SignalType != store.SignalRelativeWhale {
+		t.Fatalf("Expected a relative whale in a niche market, got %+v", signals)
+	}
+	if ratio, _ := signals[0].Meta["volume_ratio"].(float64); ratio != 0.1 {
+		t.Errorf("Expected volume ratio 0.1 in meta, got %v", signals[0].Meta["volume_ratio"])
+	}
+
+	// The percentile check needs enough recent trades to mean anything
+	recent := Enrichment{Nonce: -1, MarketVolume: 50_000_000, RecentVolume: 40000, ValueRank: 0.995}
+	recent.RecentTrades = 5
+	if signals := d.Detect(trade, recent); len(signals) != 0 {
+		t.Errorf("Expected no percentile signal with few recent trades, got %+v", signals)
+	}
+	recent.RecentTrades = 200
+	if signals := d.Detect(trade, recent); len(signals) != 1 {
+		t.Errorf("Expected a percentile signal, got %+v", signals)
+	}
+
+	// Trades below the floor never qualify
+	if signals := d.Detect(store.Trade{ValueUSD: 500}, Enrichment{Nonce: -1, MarketVolume: 1000}); len(signals) != 0 {
+		t.Errorf("Expected small trade to be ignored, got %+v", signals)
+	}
+}
+
+func TestExpressionRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	rules := `{"rules":[
+		{"name":"LONGSHOT","severity":"high","expr":"side == 'BUY' && price < 0.1 && value_usd >= 1000"},
+		{"name":"NEW_WALLET","expr":"nonce >= 0 && nonce < 3 && market_volume < 50000","min_value_usd":1000}
+	]}`
+	if err := os.WriteFile(path, []byte(rules), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadRulesFile(path)
+	if err != nil {
+		t.Fatalf("Failed to load rules: %v", err)
+	}
+	d := NewDetector(&config.Config{RulesEnabled: []string{"LONGSHOT", "NEW_WALLET"}}, clock.Real{})
+	for _, rule := range loaded {
+		if err := d.Register(rule); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	trade := store.Trade{Side: "buy", Price: 0.05, ValueUSD: 2000, MakerAddress: "0xA"}
+	if !d.ShouldEnrich(trade) {
+		t.Error("Expected a rule reading nonce to request enrichment")
+	}
+	small := trade
+	small.ValueUSD = 500
+	if d.ShouldEnrich(small) {
+		t.Error("Expected no enrichment below the rule's min_value_usd")
+	}
+
+	signals := d.Detect(trade, Enrichment{Nonce: 1, MarketVolume: 10000})
+	if len(signals) != 2 {
+		t.Fatalf("Expected both expression rules to match, got %+v", signals)
+	}
+	if signals[0].SignalType != "LONGSHOT" || signals[0].Severity != store.SeverityHigh || signals[0].Meta["expr"] == "" {
+		t.Errorf("Expected LONGSHOT suspect with its expression in meta, got %+v", signals[0])
+	}
+	if signals[1].Severity != store.SeverityMedium {
+		t.Errorf("Expected default severity medium, got %q", signals[1].Severity)
+	}
+
+	if signals := d.Detect(trade, Enrichment{Nonce: -1, MarketVolume: 10000}); len(signals) != 1 {
+		t.Errorf("Expected unknown nonce to skip the wallet rule, got %+v", signals)
+	}
+
+	// Type errors are caught when the file is loaded, not per trade
+	if _, err := CompileRule(RuleSpec{Name: "BAD", Expr: "price + 'x'"}); err == nil {
+		t.Error("Expected a type error for an invalid expression")
+	}
+	if _, err := CompileRule(RuleSpec{Name: "NOT_BOOL", Expr: "price * 2"}); err == nil {
+		t.Error("Expected non-boolean expressions to be rejected")
+	}
+	if _, err := CompileRule(RuleSpec{Name: "UNGATED", Expr: "nonce == 0"}); err == nil {
+		t.Error("Expected a wallet rule without min_value_usd to be rejected")
+	}
+}
+
+func TestFreshInsiderWalletAge(t *testing.T) {
+	cfg := &config.Config{
+		MinValueUSD:       2000,
+		FreshWalletNonce:  5,
+		FreshWalletMaxAge: 7 * 24 * time.Hour,
+		RulesEnabled:      []string{store.SignalFreshInsider},
+	}
+	d := NewDetector(cfg, clock.NewManual(time.Unix(1_700_000_000, 0)))
+	trade := store.Trade{ID: "fresh", ValueUSD: 5000, MakerAddress: "0xFresh"}
+
+	// A nonce-0 wallet funded months ago is not fresh
+	trade.Timestamp = time.Unix(1_700_000_000, 0)
+	old := Enrichment{Nonce: 0, FirstActive: trade.Timestamp.Add(-90 * 24 * time.Hour)}
+	if signals := d.Detect(trade, old); len(signals) != 0 {
+		t.Errorf("Expected no signal for an old wallet, got %v", signals)
+	}
+
+	// Age is measured at the trade, not now: a replayed trade from a month
+	// ago by a then 36h old wallet is still fresh
+	replayed := trade
+	replayed.Timestamp = time.Now().Add(-30 * 24 * time.Hour)
+	young := Enrichment{Nonce: 0, FirstActive: replayed.Timestamp.Add(-36 * time.Hour)}
+	signals := d.Detect(replayed, young)
+	if len(signals) != 1 || signals[0].Meta["wallet_age_days"] != 1.5 {
+		t.Errorf("Expected a Fresh Insider signal with its age at the trade, got %v", signals)
+	}
+
+	// Unknown age falls back to the nonce
+	if signals := d.Detect(trade, Enrichment{Nonce: 0}); len(signals) != 1 {
+		t.Errorf("Expected a Fresh Insider signal for an unknown age, got %v", signals)
+	}
+}
@@ -0,0 +1,206 @@
+package detector
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/ast"
+	"github.com/expr-lang/expr/vm"
+
+	"github.com/polyinsider/engine/internal/store"
+)
+
+// RuleSpec is one expression rule in a rules file.
+type RuleSpec struct {
+	Name        string  `json:"name"`
+	Severity    string  `json:"severity"` // low, medium, high or critical; default medium
+	Expr        string  `json:"expr"`
+	Description string  `json:"description"`
+	MinValueUSD float64 `json:"min_value_usd"` // smaller trades are skipped; required to read wallet enrichment
+}
+
+// rulesFile is the layout of RULES_FILE.
+type rulesFile struct {
+	Rules []RuleSpec `json:"rules"`
+}
+
+// exprEnv is the variable set available to rule expressions.
+type exprEnv struct {
+	ID         string    `expr:"id"`
+	TradeID    string    `expr:"trade_id"`
+	MarketID   string    `expr:"market_id"`
+	Question   string    `expr:"question"`
+	MarketSlug string    `expr:"market_slug"`
+	AssetID    string    `expr:"asset_id"`
+	Maker      string    `expr:"maker"`
+	Taker      string    `expr:"taker"`
+	Side       string    `expr:"side"`
+	Outcome    string    `expr:"outcome"`
+	Size       float64   `expr:"size"`
+	Price      float64   `expr:"price"`
+	ValueUSD   float64   `expr:"value_usd"`
+	Timestamp  time.Time `expr:"timestamp"`
+	Source     string    `expr:"source"`
+
+	Nonce         int     `expr:"nonce"`           // -1 if unknown
+	WalletAgeDays float64 `expr:"wallet_age_days"` // -1 if unknown; only looked up for fresh wallets
+	MarketVolume  float64 `expr:"market_volume"`   // lifetime USD volume, 0 if unknown
+
+	MarketLiquidity float64 `expr:"market_liquidity"` // USD liquidity, 0 if unknown
+	RecentVolume    float64 `expr:"recent_volume"`    // USD traded in the market in the last hour
+	RecentTrades    int     `expr:"recent_trades"`    // trades in the market in the last hour
+}
+
+// newExprEnv builds the expression variables for an event.
+func newExprEnv(e Event) exprEnv {
+	size, _ := strconv.ParseFloat(e.Trade.Size, 64)
+
+	walletAge := -1.0
+	if e.WalletAge > 0 {
+		walletAge = e.WalletAge.Hours() / 24
+	}
+
+	return exprEnv{
+		ID:            e.Trade.ID,
+		TradeID:       e.Trade.TradeID,
+		MarketID:      e.Trade.MarketID,
+		Question:      e.Trade.Question,
+		MarketSlug:    e.Trade.MarketSlug,
+		AssetID:       e.Trade.AssetID,
+		Maker:         e.Trade.MakerAddress,
+		Taker:         e.Trade.TakerAddress,
+		Side:          strings.ToUpper(e.Trade.Side),
+		Outcome:       e.Trade.Outcome,
+		Size:          size,
+		Price:         e.Trade.Price,
+		ValueUSD:      e.Trade.ValueUSD,
+		Timestamp:     e.Time,
+		Source:        e.Trade.Source,
+		Nonce:         e.Nonce,
+		WalletAgeDays: walletAge,
+		MarketVolume:  e.MarketVolume,
+
+		MarketLiquidity: e.MarketLiquidity,
+		RecentVolume:    e.RecentVolume,
+		RecentTrades:    e.RecentTrades,
+	}
+}
+
+// exprRule is a rule defined by a boolean expression over exprEnv.
+type exprRule struct {
+	name       string
+	severity   string
+	source     string
+	program    *vm.Program
+	minValue   float64 // trades below this are not evaluated
+	wantsNonce bool    // the expression reads wallet enrichment
+}
+
+// CompileRule type-checks spec and returns it as a Rule.
+func CompileRule(spec RuleSpec) (Rule, error) {
+	name := strings.TrimSpace(spec.Name)
+	if name == "" {
+		return nil, fmt.Errorf("rule has no name")
+	}
+	if strings.TrimSpace(spec.Expr) == "" {
+		return nil, fmt.Errorf("rule %s has no expression", name)
+	}
+
+	severity := strings.ToLower(spec.Severity)
+	switch severity {
+	case "":
+		severity = store.SeverityMedium
+	case store.SeverityLow, store.SeverityMedium, store.SeverityHigh, store.SeverityCritical:
+	default:
+		return nil, fmt.Errorf("rule %s: unknown severity %q", name, spec.Severity)
+	}
+
+	program, err := expr.Compile(spec.Expr, expr.Env(exprEnv{}), expr.AsBool())
+	if err != nil {
+		return nil, fmt.Errorf("rule %s: %w", name, err)
+	}
+
+	idents := identifiers{}
+	node := program.Node()
+	ast.Walk(&node, idents)
+
+	// Wallet enrichment costs an RPC lookup per trade; gate it on value
+	wantsNonce := idents["nonce"] || idents["wallet_age_days"]
+	if wantsNonce && spec.MinValueUSD <= 0 {
+		return nil, fmt.Errorf("rule %s reads nonce or wallet_age_days and needs min_value_usd", name)
+	}
+
+	return &exprRule{
+		name:       name,
+		severity:   severity,
+		source:     spec.Expr,
+		program:    program,
+		minValue:   spec.MinValueUSD,
+		wantsNonce: wantsNonce,
+	}, nil
+}
+
+// identifiers collects the variable names an expression reads.
+type identifiers map[string]bool
+
+func (ids identifiers) Visit(node *ast.Node) {
+	if ident, ok := (*node).(*ast.IdentifierNode); ok {
+		ids[ident.Value] = true
+	}
+}
+
+// LoadRulesFile reads and compiles every rule in a JSON rules file.
+func LoadRulesFile(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules file: %w", err)
+	}
+
+	var file rulesFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse rules file: %w", err)
+	}
+
+	rules := make([]Rule, 0, len(file.Rules))
+	for _, spec := range file.Rules {
+		rule, err := CompileRule(spec)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func (r *exprRule) Name() string     { return r.name }
+func (r *exprRule) Severity() string { return r.severity }
+
+// WantsNonce requests wallet enrichment only for rules that read it, and
+// only for trades worth at least the rule's minimum value.
+func (r *exprRule) WantsNonce(trade store.Trade) bool {
+	return r.wantsNonce && trade.ValueUSD >= r.minValue && trade.MakerAddress != ""
+}
+
+func (r *exprRule) Evaluate(e Event) []store.Suspect {
+	if e.Trade.ValueUSD < r.minValue {
+		return nil
+	}
+	out, err := expr.Run(r.program, newExprEnv(e))
+	if err != nil {
+		slog.Debug("rule_eval_failed", "rule", r.name, "error", err)
+		return nil
+	}
+	if matched, _ := out.(bool); !matched {
+		return nil
+	}
+	return []store.Suspect{e.Suspect(map[string]interface{}{
+		"rule": r.name,
+		"expr": r.source,
+	})}
+}
@@ -0,0 +1,193 @@
+package detector
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/polyinsider/engine/internal/clock"
+	"github.com/polyinsider/engine/internal/store"
+)
+
+// attributionWindow is how close in event time a trade by a known wallet
+// must be to an anonymous trade at the same price to be credited with its move.
+const attributionWindow = 10 * time.Second
+
+// priceShockRule flags a trade that moves an asset's price within the
+// lookback window by at least pct (relative) and points (absolute probability).
+// The move is measured against the price in the window furthest from the
+// trade's, so a climb spread over several trades is caught as one shock.
+type priceShockRule struct {
+	pct      float64 // relative move, e.g. 0.05; 0 disables the check
+	points   float64 // absolute move in probability, e.g. 0.02; 0 disables the check
+	window   time.Duration
+	minValue float64
+
+	mu        sync.Mutex
+	assets    map[string]*assetPrices // assetID -> recent prices
+	watermark *clock.Watermark
+}
+
+// assetPrices is the recent price history of one asset.
+type assetPrices struct {
+	points []pricePoint // sorted by event time
+	shock  *priceShock  // last shock reported
+}
+
+// pricePoint is a price observed at an event time.
+type pricePoint struct {
+	price float64
+	at    time.Time
+	maker string
+}
+
+// priceShock is a reported move, kept to avoid re-reporting it.
+type priceShock struct {
+	pricePoint
+	up bool
+}
+
+func newPriceShockRule(pct, points float64, window, lateness time.Duration, minValue float64) *priceShockRule {
+	return &priceShockRule{
+		pct:       pct,
+		points:    points,
+		window:    window,
+		minValue:  minValue,
+		assets:    make(map[string]*assetPrices),
+		watermark: clock.NewWatermark(lateness),
+	}
+}
+
+func (r *priceShockRule) Name() string     { return store.SignalPriceShock }
+func (r *priceShockRule) Severity() string { return store.SeverityLow }
+
+func (r *priceShockRule) Evaluate(e Event) []store.Suspect {
+	trade := e.Trade
+	if trade.Price <= 0 || trade.AssetID == "" {
+		return nil
+	}
+	r.watermark.Observe(e.Time)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	prices, exists := r.assets[trade.AssetID]
+	if !exists {
+		prices = &assetPrices{}
+		r.assets[trade.AssetID] = prices
+	}
+	prices.points = evictPricesBefore(prices.points, r.watermark.Current().Add(-r.window))
+
+	// Every priced trade sets the reference, but only sized ones can be a
+	// shock; zero-value prints would otherwise fire on their own
+	ref, found := prices.reference(trade.Price, e.Time, r.window)
+	prices.insert(pricePoint{price: trade.Price, at: e.Time, maker: trade.MakerAddress})
+
+	if !found || ref.price <= 0 || trade.ValueUSD < r.minValue {
+		return nil
+	}
+
+	pointChange := trade.Price - ref.price
+	pctChange := math.Abs(pointChange) / ref.price
+	if pctChange < r.pct || math.Abs(pointChange) < r.points {
+		return nil
+	}
+
+	// Credit an anonymous print to the wallet that traded at that price
+	maker := trade.MakerAddress
+	attributed := false
+	if maker == "" {
+		maker = prices.makerAt(trade.Price, e.Time)
+		attributed = maker != ""
+	}
+
+	// A move already reported is only reported again to name its wallet
+	up := pointChange > 0
+	if last := prices.shock; last != nil && last.up == up && e.Time.Sub(last.at) < r.window {
+		further := (up && trade.Price > last.price) || (!up && trade.Price < last.price)
+		if !further && (last.maker != "" || maker == "") {
+			return nil
+		}
+	}
+	prices.shock = &priceShock{pricePoint: pricePoint{price: trade.Price, at: e.Time, maker: maker}, up: up}
+
+	meta := map[string]interface{}{
+		"prev_price":     ref.price,
+		"new_price":      trade.Price,
+		"pct_change":     pctChange,
+		"point_change":   pointChange,
+		"window_seconds": r.window.Seconds(),
+	}
+	suspect := e.Suspect(meta)
+	if attributed {
+		suspect.Trade.MakerAddress = maker
+		meta["attributed"] = true
+	}
+	return []store.Suspect{suspect}
+}
+
+// Cleanup drops assets with no trades in the window.
+func (r *priceShockRule) Cleanup() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cutoff := r.watermark.Current().Add(-r.window)
+	for assetID, prices := range r.assets {
+		if len(prices.points) == 0 || prices.points[len(prices.points)-1].at.Before(cutoff) {
+			delete(r.assets, assetID)
+		}
+	}
+}
+
+// reference returns the price within window before at that is furthest from price.
+func (a *assetPrices) reference(price float64, at time.Time, window time.Duration) (pricePoint, bool) {
+	var ref pricePoint
+	found := false
+	for _, p := range a.points {
+		if p.at.After(at) {
+			break
+		}
+		if at.Sub(p.at) > window {
+			continue
+		}
+		if !found || math.Abs(p.price-price) > math.Abs(ref.price-price) {
+			ref, found = p, true
+		}
+	}
+	return ref, found
+}
+
+// insert adds p in event-time order.
+func (a *assetPrices) insert(p pricePoint) {
+	i := sort.Search(len(a.points), func(i int) bool { return a.points[i].at.After(p.at) })
+	a.points = append(a.points, pricePoint{})
+	copy(a.points[i+1:], a.points[i:])
+	a.points[i] = p
+}
+
+// makerAt returns the wallet of the nearest trade at price within the
+// attribution window of at, or "" if none is known.
+func (a *assetPrices) makerAt(price float64, at time.Time) string {
+	maker := ""
+	nearest := attributionWindow
+	for _, p := range a.points {
+		if p.maker == "" || math.Abs(p.price-price) > 1e-9 {
+			continue
+		}
+		gap := p.at.Sub(at)
+		if gap < 0 {
+			gap = -gap
+		}
+		if gap <= nearest {
+			maker, nearest = p.maker, gap
+		}
+	}
+	return maker
+}
+
+// evictPricesBefore drops the leading points older than cutoff.
+func evictPricesBefore(points []pricePoint, cutoff time.Time) []pricePoint {
+	i := sort.Search(len(points), func(i int) bool { return !points[i].at.Before(cutoff) })
+	return points[i:]
+}
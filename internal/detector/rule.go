@@ -0,0 +1,125 @@
+package detector
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/polyinsider/engine/internal/store"
+)
+
+// Enrichment is the per-trade context gathered before detection.
+type Enrichment struct {
+	Nonce        int           // wallet transaction count, -1 if unavailable
+	FirstActive  time.Time     // block time of the maker's first activity, zero if unknown
+	WalletAge    time.Duration // maker's age at the trade's event time, 0 if unknown; set by Detect from FirstActive
+	MarketVolume float64       // lifetime market volume in USD, 0 if unknown
+
+	MarketLiquidity float64 // order book liquidity in USD, 0 if unknown
+	RecentVolume    float64 // USD traded in the market over the tracker window before this trade
+	RecentTrades    int     // trades in the market over the tracker window before this trade
+	ValueRank       float64 // share of those trades worth less than this one
+}
+
+// Event is the input to a rule: a trade, its event time and its enrichment.
+type Event struct {
+	Trade store.Trade
+	Time  time.Time // event time (trade timestamp, or the clock if missing)
+	Wash  bool      // a WashFilter rule recognises the trade as a wash trade
+	Enrichment
+}
+
+// Rule is one detection heuristic. Rules are evaluated in registration
+// order for every trade, after any WashFilter rules so Event.Wash is set,
+// and may keep state between trades; the Detector
+// serialises nothing, so stateful rules must be safe for concurrent use.
+type Rule interface {
+	// Name is the signal type the rule emits, e.g. store.SignalWhale.
+	Name() string
+	// Severity is the default severity of the rule's suspects.
+	Severity() string
+	// Evaluate returns the suspects the event triggers, if any.
+	Evaluate(event Event) []store.Suspect
+}
+
+// Cleaner is implemented by rules that hold state needing periodic pruning.
+type Cleaner interface {
+	Cleanup()
+}
+
+// NonceRule is implemented by rules that need the maker's wallet nonce.
+// The worker only pays for the RPC lookup when some rule wants it.
+type NonceRule interface {
+	WantsNonce(trade store.Trade) bool
+}
+
+// WashFilter is implemented by rules that recognise wash trades. The
+// worker keeps such trades out of market activity whether or not they
+// raised a suspect.
+type WashFilter interface {
+	IsWash(trade store.Trade) bool
+}
+
+// Registry is an ordered set of rules with unique names.
+type Registry struct {
+	rules []Rule
+	names map[string]bool
+}
+
+// NewRegistry creates an empty rule registry.
+func NewRegistry() *Registry {
+	return &Registry{names: make(map[string]bool)}
+}
+
+// Register appends rule. Names must be unique.
+func (r *Registry) Register(rule Rule) error {
+	name := rule.Name()
+	if name == "" {
+		return fmt.Errorf("rule has no name")
+	}
+	if r.names[name] {
+		return fmt.Errorf("rule %s already registered", name)
+	}
+	r.names[name] = true
+	r.rules = append(r.rules, rule)
+	return nil
+}
+
+// Rules returns the registered rules in order.
+func (r *Registry) Rules() []Rule {
+	return r.rules
+}
+
+// Has reports whether a rule with the given name is registered.
+func (r *Registry) Has(name string) bool {
+	return r.names[name]
+}
+
+// Buying reports whether the trade's side is BUY; ok is false when the side is unknown.
+func (e Event) Buying() (buy, ok bool) {
+	switch strings.ToUpper(e.Trade.Side) {
+	case "BUY":
+		return true, true
+	case "SELL":
+		return false, true
+	}
+	return false, false
+}
+
+// TradeID returns the trade's ID, falling back to the source trade ID.
+func (e Event) TradeID() string {
+	if e.Trade.ID != "" {
+		return e.Trade.ID
+	}
+	return e.Trade.TradeID
+}
+
+// Suspect builds a suspect for the event's trade. The Detector fills in the
+// signal type and severity from the rule when they are left empty.
+func (e Event) Suspect(meta map[string]interface{}) store.Suspect {
+	return store.Suspect{
+		Trade: e.Trade,
+		Nonce: e.Nonce,
+		Meta:  meta,
+	}
+}
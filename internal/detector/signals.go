@@ -1,105 +1,145 @@
 package detector
 
 import (
-	"math"
-	"sync"
+	"log/slog"
+	"strings"
 
+	"github.com/polyinsider/engine/internal/clock"
 	"github.com/polyinsider/engine/internal/config"
 	"github.com/polyinsider/engine/internal/store"
 )
 
-// Detector applies rules to detect suspicious trading activity.
+// Detector runs every registered rule against each trade.
 type Detector struct {
-	cfg          *config.Config
-	burstTracker *BurstTracker
-	
-	mu         sync.RWMutex
-	lastPrices map[string]float64 // assetID -> last price
+	cfg      *config.Config
+	clock    clock.Clock
+	registry *Registry
 }
 
-// NewDetector creates a new Detector.
-func NewDetector(cfg *config.Config) *Detector {
-	return &Detector{
-		cfg:          cfg,
-		burstTracker: NewBurstTracker(cfg.BurstWindow),
-		lastPrices:   make(map[string]float64),
+// NewDetector creates a Detector with the built-in rules enabled by cfg.
+// clk supplies the time for trades without a timestamp; all windows are
+// otherwise computed from Trade.Timestamp.
+func NewDetector(cfg *config.Config, clk clock.Clock) *Detector {
+	d := &Detector{
+		cfg:      cfg,
+		clock:    clk,
+		registry: NewRegistry(),
 	}
+	for _, rule := range DefaultRules(cfg) {
+		if err := d.Register(rule); err != nil {
+			slog.Warn("rule_register_failed", "rule", rule.Name(), "error", err)
+		}
+	}
+	return d
 }
 
-// Detect analyzes a trade and returns any signals found.
-// nonce should be -1 if not available/enriched yet.
-func (d *Detector) Detect(trade store.Trade, nonce int) []store.Suspect {
-	var suspects []store.Suspect
+// Register adds a rule unless config disables it. Call before Detect is used.
+func (d *Detector) Register(rule Rule) error {
+	if !d.enabled(rule.Name()) {
+		slog.Info("rule_disabled", "rule", rule.Name())
+		return nil
+	}
+	return d.registry.Register(rule)
+}
 
-	// Check 1: Price Shock (Impact > 5%)
-	// Must happen before we update lastPrices
-	d.mu.Lock()
-	lastPrice, exists := d.lastPrices[trade.AssetID]
-	d.lastPrices[trade.AssetID] = trade.Price
-	d.mu.Unlock()
+// Rules returns the names of the active rules in evaluation order.
+func (d *Detector) Rules() []string {
+	names := make([]string, 0, len(d.registry.Rules()))
+	for _, rule := range d.registry.Rules() {
+		names = append(names, rule.Name())
+	}
+	return names
+}
 
-	if exists && lastPrice > 0 {
-		// Calculate percentage change: |new - old| / old
-		delta := math.Abs(trade.Price - lastPrice)
-		pctChange := delta / lastPrice
+// Detect analyzes a trade and returns any signals found.
+// enrichment.Nonce should be -1 if not available/enriched yet.
+func (d *Detector) Detect(trade store.Trade, enrichment Enrichment) []store.Suspect {
+	event := Event{
+		Trade:      trade,
+		Time:       clock.EventTime(trade.Timestamp, d.clock),
+		Enrichment: enrichment,
+	}
+	// Age as of the trade, not now, so replayed trades see the age they had
+	if !enrichment.FirstActive.IsZero() {
+		event.WalletAge = event.Time.Sub(enrichment.FirstActive)
+	}
 
-		// 5% threshold (0.05)
-		if pctChange >= 0.05 {
-			suspects = append(suspects, store.Suspect{
-				Trade:      trade,
-				SignalType: store.SignalPriceShock,
-				Nonce:      nonce,
-				Meta: map[string]interface{}{
-					"prev_price": lastPrice,
-					"new_price":  trade.Price,
-					"pct_change": pctChange,
-				},
-			})
+	// Wash filters see the trade first so other rules can leave wash trades
+	// out; suspects are still returned in registration order
+	rules := d.registry.Rules()
+	results := make([][]store.Suspect, len(rules))
+	for i, rule := range rules {
+		if _, ok := rule.(WashFilter); ok {
+			results[i] = rule.Evaluate(event)
+		}
+	}
+	event.Wash = d.IsWash(trade)
+	for i, rule := range rules {
+		if _, ok := rule.(WashFilter); !ok {
+			results[i] = rule.Evaluate(event)
 		}
 	}
 
-	// Check 2: Whale
-	// IF value_usd > 50000 THEN ALERT
-	if trade.ValueUSD >= d.cfg.WhaleValueUSD {
-		suspects = append(suspects, store.Suspect{
-			Trade:      trade,
-			SignalType: store.SignalWhale,
-			Nonce:      nonce,
-		})
+	var suspects []store.Suspect
+	for i, rule := range rules {
+		for _, suspect := range results[i] {
+			if suspect.SignalType == "" {
+				suspect.SignalType = rule.Name()
+			}
+			if suspect.Severity == "" {
+				suspect.Severity = rule.Severity()
+			}
+			suspects = append(suspects, suspect)
+		}
 	}
+	return suspects
+}
 
-	// Check 3: Fresh Insider
-	// IF value_usd > 2000 AND wallet_nonce < 5 THEN ALERT
-	// We only check this if nonce is provided (>= 0)
-	if nonce >= 0 && trade.ValueUSD >= d.cfg.MinValueUSD {
-		if nonce <= d.cfg.FreshWalletNonce {
-			suspects = append(suspects, store.Suspect{
-				Trade:      trade,
-				SignalType: store.SignalFreshInsider,
-				Nonce:      nonce,
-			})
+// ShouldEnrich checks if a trade qualifies for expensive RPC enrichment (nonce check).
+func (d *Detector) ShouldEnrich(trade store.Trade) bool {
+	for _, rule := range d.registry.Rules() {
+		if nr, ok := rule.(NonceRule); ok && nr.WantsNonce(trade) {
+			return true
 		}
 	}
+	return false
+}
 
-	// Check 4: Panic Burst
-	// IF trades_from_address_in_last_60s >= 3 THEN ALERT
-	if trade.MakerAddress != "" {
-		count := d.burstTracker.Record(trade.MakerAddress)
-		if count >= d.cfg.BurstCount {
-			suspects = append(suspects, store.Suspect{
-				Trade:      trade,
-				SignalType: store.SignalPanicBurst,
-				Nonce:      nonce,
-			})
+// IsWash reports whether a rule recognises trade as a wash trade. Call it
+// after Detect so the rules have seen the trade.
+func (d *Detector) IsWash(trade store.Trade) bool {
+	for _, rule := range d.registry.Rules() {
+		if wf, ok := rule.(WashFilter); ok && wf.IsWash(trade) {
+			return true
 		}
 	}
+	return false
+}
 
-	return suspects
+// Cleanup prunes the state of rules that keep any.
+// Should be called periodically to prevent memory leaks.
+func (d *Detector) Cleanup() {
+	for _, rule := range d.registry.Rules() {
+		if c, ok := rule.(Cleaner); ok {
+			c.Cleanup()
+		}
+	}
 }
 
-// ShouldEnrich checks if a trade qualifies for expensive RPC enrichment (nonce check).
-func (d *Detector) ShouldEnrich(trade store.Trade) bool {
-	// Only enrich if value is high enough to be a potential Fresh Insider
-	// and we have a Maker Address
-	return trade.ValueUSD >= d.cfg.MinValueUSD && trade.MakerAddress != ""
+// enabled applies RULES_ENABLED and RULES_DISABLED to a rule name.
+func (d *Detector) enabled(name string) bool {
+	if len(d.cfg.RulesEnabled) > 0 && !containsFold(d.cfg.RulesEnabled, name) {
+		return false
+	}
+	return !containsFold(d.cfg.RulesDisabled, name)
+}
+
+// containsFold reports whether list contains s, ignoring case.
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,133 @@
+package detector
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/polyinsider/engine/internal/clock"
+	"github.com/polyinsider/engine/internal/store"
+)
+
+// swarmTrade is one fresh wallet's trade in a swarm.
+type swarmTrade struct {
+	at       time.Time
+	wallet   string
+	notional float64
+}
+
+// swarm is the recent fresh-wallet trading on one side of one asset.
+type swarm struct {
+	trades []swarmTrade // sorted by event time
+	fired  bool         // reported and not since dropped below the wallet count
+}
+
+// freshSwarmRule flags minWallets or more distinct fresh wallets (nonce at
+// most maxNonce) trading the same side of one asset within the window.
+type freshSwarmRule struct {
+	minWallets int
+	maxNonce   int
+	minValue   float64 // smaller trades neither join a swarm nor request a nonce
+	window     time.Duration
+
+	mu        sync.Mutex
+	swarms    map[string]*swarm // asset|side -> fresh-wallet trades
+	watermark *clock.Watermark
+}
+
+func newFreshSwarmRule(minWallets, maxNonce int, minValue float64, window, lateness time.Duration) *freshSwarmRule {
+	return &freshSwarmRule{
+		minWallets: minWallets,
+		maxNonce:   maxNonce,
+		minValue:   minValue,
+		window:     window,
+		swarms:     make(map[string]*swarm),
+		watermark:  clock.NewWatermark(lateness),
+	}
+}
+
+func (r *freshSwarmRule) Name() string     { return store.SignalFreshSwarm }
+func (r *freshSwarmRule) Severity() string { return store.SeverityHigh }
+
+// WantsNonce requests nonces for every trade that could join a swarm.
+func (r *freshSwarmRule) WantsNonce(trade store.Trade) bool {
+	return r.minWallets > 0 && trade.ValueUSD >= r.minValue && trade.MakerAddress != ""
+}
+
+func (r *freshSwarmRule) Evaluate(e Event) []store.Suspect {
+	if !r.WantsNonce(e.Trade) || e.Nonce < 0 || e.Nonce > r.maxNonce || e.Trade.AssetID == "" {
+		return nil
+	}
+	buy, ok := e.Buying()
+	if !ok {
+		return nil
+	}
+	direction := "SELL"
+	if buy {
+		direction = "BUY"
+	}
+
+	r.watermark.Observe(e.Time)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := e.Trade.AssetID + "|" + direction
+	s, exists := r.swarms[key]
+	if !exists {
+		s = &swarm{}
+		r.swarms[key] = s
+	}
+
+	cutoff := r.watermark.Current().Add(-r.window)
+	first := sort.Search(len(s.trades), func(i int) bool { return !s.trades[i].at.Before(cutoff) })
+	s.trades = s.trades[first:]
+
+	i := sort.Search(len(s.trades), func(i int) bool { return s.trades[i].at.After(e.Time) })
+	s.trades = append(s.trades, swarmTrade{})
+	copy(s.trades[i+1:], s.trades[i:])
+	s.trades[i] = swarmTrade{at: e.Time, wallet: strings.ToLower(e.Trade.MakerAddress), notional: e.Trade.ValueUSD}
+
+	// Distinct wallets in first-trade order, with their combined notional
+	seen := make(map[string]bool)
+	wallets := make([]string, 0, len(s.trades))
+	combined := 0.0
+	for _, t := range s.trades {
+		combined += t.notional
+		if !seen[t.wallet] {
+			seen[t.wallet] = true
+			wallets = append(wallets, t.wallet)
+		}
+	}
+
+	if len(wallets) < r.minWallets {
+		s.fired = false
+		return nil
+	}
+	if s.fired {
+		return nil
+	}
+	s.fired = true
+
+	return []store.Suspect{e.Suspect(map[string]interface{}{
+		"wallets":        wallets,
+		"wallet_count":   len(wallets),
+		"combined_usd":   combined,
+		"direction":      direction,
+		"window_minutes": r.window.Minutes(),
+	})}
+}
+
+// Cleanup drops swarms with no trades in the window.
+func (r *freshSwarmRule) Cleanup() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cutoff := r.watermark.Current().Add(-r.window)
+	for key, s := range r.swarms {
+		if len(s.trades) == 0 || s.trades[len(s.trades)-1].at.Before(cutoff) {
+			delete(r.swarms, key)
+		}
+	}
+}
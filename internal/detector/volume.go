@@ -0,0 +1,174 @@
+package detector
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/polyinsider/engine/internal/clock"
+	"github.com/polyinsider/engine/internal/store"
+)
+
+const (
+	// volumeBucket is the width of the buckets volume is baselined over.
+	volumeBucket = time.Minute
+	// maxIdleBuckets caps how many empty minutes are folded into a baseline
+	// at once; a market idle for longer is treated as idle for this long.
+	maxIdleBuckets = 24 * 60
+	// volumeStateTTL is how long a market without trades keeps its baseline.
+	volumeStateTTL = 24 * time.Hour
+)
+
+// ewma is an exponentially weighted mean and variance.
+type ewma struct {
+	mean     float64
+	variance float64
+}
+
+// add folds x into the average with weight alpha.
+func (w *ewma) add(x, alpha float64) {
+	diff := x - w.mean
+	incr := alpha * diff
+	w.mean += incr
+	w.variance = (1 - alpha) * (w.variance + diff*incr)
+}
+
+// zscore returns how many standard deviations x is above the mean. The
+// deviation is floored so a market that has always been flat still scores.
+func (w *ewma) zscore(x, minStdDev float64) float64 {
+	return (x - w.mean) / math.Max(math.Sqrt(w.variance), minStdDev)
+}
+
+// marketVolume is the per-minute activity baseline of one market.
+type marketVolume struct {
+	bucket  time.Time // start of the current minute
+	volume  float64   // USD traded in the current minute
+	trades  int       // trades in the current minute
+	minutes int       // closed minutes folded into the baseline
+	fired   bool      // an anomaly was reported for the current minute
+
+	volumeAvg ewma
+	tradesAvg ewma
+}
+
+// volumeAnomalyRule flags markets whose volume or trade count in the current
+// minute is zThreshold standard deviations above their EWMA baseline; a
+// zThreshold of 0 disables the rule.
+// Minutes are event-time buckets; a trade older than the current minute is
+// counted in the current one. Wash trades are fake volume and not counted.
+//
+// The baseline is kept here rather than derived from the metrics tracker's
+// market activity, which holds only the last hour of trades and is updated
+// after detection; the EWMA remembers far longer at a fixed cost per market.
+type volumeAnomalyRule struct {
+	zThreshold float64
+	alpha      float64 // EWMA weight of each closed minute
+	minMinutes int     // closed minutes needed before a market can fire
+	minValue   float64 // USD the minute must reach before it can fire
+
+	mu        sync.Mutex
+	markets   map[string]*marketVolume
+	watermark *clock.Watermark
+}
+
+func newVolumeAnomalyRule(zThreshold, alpha float64, minMinutes int, minValue float64, lateness time.Duration) *volumeAnomalyRule {
+	if alpha <= 0 || alpha > 1 {
+		alpha = 0.1
+	}
+	return &volumeAnomalyRule{
+		zThreshold: zThreshold,
+		alpha:      alpha,
+		minMinutes: minMinutes,
+		minValue:   minValue,
+		markets:    make(map[string]*marketVolume),
+		watermark:  clock.NewWatermark(lateness),
+	}
+}
+
+func (r *volumeAnomalyRule) Name() string     { return store.SignalVolumeAnomaly }
+func (r *volumeAnomalyRule) Severity() string { return store.SeverityMedium }
+
+func (r *volumeAnomalyRule) Evaluate(e Event) []store.Suspect {
+	marketID := e.Trade.MarketID
+	if marketID == "" {
+		marketID = e.Trade.AssetID
+	}
+	if marketID == "" || e.Wash {
+		return nil
+	}
+	r.watermark.Observe(e.Time)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	bucket := e.Time.Truncate(volumeBucket)
+	market, exists := r.markets[marketID]
+	if !exists {
+		market = &marketVolume{bucket: bucket}
+		r.markets[marketID] = market
+	}
+	if bucket.After(market.bucket) {
+		r.roll(market, bucket)
+	}
+
+	market.volume += e.Trade.ValueUSD
+	market.trades++
+
+	if r.zThreshold <= 0 || market.fired || market.minutes < r.minMinutes || market.volume < r.minValue {
+		return nil
+	}
+
+	volumeZ := market.volumeAvg.zscore(market.volume, 1)
+	tradesZ := market.tradesAvg.zscore(float64(market.trades), 0.5)
+	if volumeZ < r.zThreshold && tradesZ < r.zThreshold {
+		return nil
+	}
+	market.fired = true
+
+	meta := map[string]interface{}{
+		"baseline_volume": market.volumeAvg.mean,
+		"observed_volume": market.volume,
+		"volume_z":        volumeZ,
+		"baseline_trades": market.tradesAvg.mean,
+		"observed_trades": market.trades,
+		"trades_z":        tradesZ,
+		"bucket":          market.bucket,
+	}
+	if market.volumeAvg.mean > 0 {
+		meta["volume_multiple"] = market.volume / market.volumeAvg.mean
+	}
+	return []store.Suspect{e.Suspect(meta)}
+}
+
+// roll closes the market's current minute and any empty minutes up to bucket.
+// Must be called with lock held.
+func (r *volumeAnomalyRule) roll(market *marketVolume, bucket time.Time) {
+	market.volumeAvg.add(market.volume, r.alpha)
+	market.tradesAvg.add(float64(market.trades), r.alpha)
+	market.minutes++
+
+	idle := int(bucket.Sub(market.bucket)/volumeBucket) - 1
+	for i := 0; i < min(idle, maxIdleBuckets); i++ {
+		market.volumeAvg.add(0, r.alpha)
+		market.tradesAvg.add(0, r.alpha)
+		market.minutes++
+	}
+
+	market.bucket = bucket
+	market.volume = 0
+	market.trades = 0
+	market.fired = false
+}
+
+// Cleanup drops markets with no trades for a day.
+func (r *volumeAnomalyRule) Cleanup() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cutoff := r.watermark.Current().Add(-volumeStateTTL)
+	for marketID, market := range r.markets {
+		if market.bucket.Before(cutoff) {
+			delete(r.markets, marketID)
+		}
+	}
+}
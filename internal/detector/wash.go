@@ -0,0 +1,174 @@
+package detector
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/polyinsider/engine/internal/clock"
+	"github.com/polyinsider/engine/internal/store"
+)
+
+// Wash trade patterns recorded in Suspect.Meta["pattern"].
+const (
+	WashSelfMatch = "self_match"
+	WashPingPong  = "ping_pong"
+)
+
+// pairTrade is one trade between the two wallets of a pair.
+type pairTrade struct {
+	at      time.Time
+	forward bool // the pair's first wallet (by address order) bought
+	tradeID string
+}
+
+// walletPair is the recent trading between two wallets on one asset.
+type walletPair struct {
+	trades []pairTrade // sorted by event time
+	fired  bool        // reported and not since dropped below the round trips
+}
+
+// roundTrips counts the direction changes between consecutive trades.
+func (p *walletPair) roundTrips() int {
+	trips := 0
+	for i := 1; i < len(p.trades); i++ {
+		if p.trades[i].forward != p.trades[i-1].forward {
+			trips++
+		}
+	}
+	return trips
+}
+
+// washTradeRule flags trades a wallet makes against itself, and pairs of
+// wallets trading one asset back and forth at least minRoundTrips times
+// within the window.
+type washTradeRule struct {
+	window        time.Duration
+	minRoundTrips int
+
+	mu        sync.Mutex
+	pairs     map[string]*walletPair // wallet|wallet|asset -> trades
+	watermark *clock.Watermark
+}
+
+func newWashTradeRule(window time.Duration, minRoundTrips int, lateness time.Duration) *washTradeRule {
+	if minRoundTrips < 1 {
+		minRoundTrips = 1
+	}
+	return &washTradeRule{
+		window:        window,
+		minRoundTrips: minRoundTrips,
+		pairs:         make(map[string]*walletPair),
+		watermark:     clock.NewWatermark(lateness),
+	}
+}
+
+func (r *washTradeRule) Name() string     { return store.SignalWashTrade }
+func (r *washTradeRule) Severity() string { return store.SeverityMedium }
+
+func (r *washTradeRule) Evaluate(e Event) []store.Suspect {
+	maker := strings.ToLower(e.Trade.MakerAddress)
+	taker := strings.ToLower(e.Trade.TakerAddress)
+	if maker == "" || taker == "" {
+		return nil
+	}
+	if maker == taker {
+		return []store.Suspect{e.Suspect(map[string]interface{}{"pattern": WashSelfMatch})}
+	}
+
+	// Orient the side to the pair so both wallets share one key
+	makerBought, ok := e.Buying()
+	if !ok {
+		return nil
+	}
+	key, first := pairKey(maker, taker, e.Trade.AssetID)
+	forward := makerBought == (maker == first)
+
+	r.watermark.Observe(e.Time)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pair, exists := r.pairs[key]
+	if !exists {
+		pair = &walletPair{}
+		r.pairs[key] = pair
+	}
+
+	cutoff := r.watermark.Current().Add(-r.window)
+	oldest := sort.Search(len(pair.trades), func(i int) bool { return !pair.trades[i].at.Before(cutoff) })
+	pair.trades = pair.trades[oldest:]
+
+	i := sort.Search(len(pair.trades), func(i int) bool { return pair.trades[i].at.After(e.Time) })
+	pair.trades = append(pair.trades, pairTrade{})
+	copy(pair.trades[i+1:], pair.trades[i:])
+	pair.trades[i] = pairTrade{at: e.Time, forward: forward, tradeID: e.TradeID()}
+
+	trips := pair.roundTrips()
+	if trips < r.minRoundTrips {
+		pair.fired = false
+		return nil
+	}
+	if pair.fired {
+		return nil
+	}
+	pair.fired = true
+
+	tradeIDs := make([]string, 0, len(pair.trades))
+	for _, t := range pair.trades {
+		tradeIDs = append(tradeIDs, t.tradeID)
+	}
+
+	return []store.Suspect{e.Suspect(map[string]interface{}{
+		"pattern":      WashPingPong,
+		"counterparty": e.Trade.TakerAddress,
+		"round_trips":  trips,
+		"trade_ids":    tradeIDs,
+	})}
+}
+
+// IsWash reports whether trade is a self-match or between a pair at or
+// above the round trips in the window, including trades after the one that
+// raised the suspect.
+func (r *washTradeRule) IsWash(trade store.Trade) bool {
+	maker := strings.ToLower(trade.MakerAddress)
+	taker := strings.ToLower(trade.TakerAddress)
+	if maker == "" || taker == "" {
+		return false
+	}
+	if maker == taker {
+		return true
+	}
+
+	key, _ := pairKey(maker, taker, trade.AssetID)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pair, exists := r.pairs[key]
+	return exists && pair.roundTrips() >= r.minRoundTrips
+}
+
+// pairKey returns the key of the pair of lower-cased wallets on asset and
+// the pair's first wallet by address order.
+func pairKey(maker, taker, asset string) (key, first string) {
+	first, second := maker, taker
+	if second < first {
+		first, second = second, first
+	}
+	return first + "|" + second + "|" + asset, first
+}
+
+// Cleanup drops pairs with no trades in the window.
+func (r *washTradeRule) Cleanup() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cutoff := r.watermark.Current().Add(-r.window)
+	for key, pair := range r.pairs {
+		if len(pair.trades) == 0 || pair.trades[len(pair.trades)-1].at.Before(cutoff) {
+			delete(r.pairs, key)
+		}
+	}
+}
@@ -0,0 +1,308 @@
+package enrich
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/polyinsider/engine/internal/config"
+)
+
+const (
+	// DefaultAgeCacheTTL is how long a wallet's first activity is cached.
+	// It never changes, so the TTL only bounds memory.
+	DefaultAgeCacheTTL = 24 * time.Hour
+	// NoActivityRetryAfter is how long a wallet with no activity is not searched again.
+	NoActivityRetryAfter = time.Hour
+	// ageLookupTimeout bounds one binary search, which makes dozens of calls.
+	ageLookupTimeout = 20 * time.Second
+	// ageQueueSize bounds the wallets waiting for a background search;
+	// wallets beyond it are dropped and requested again by a later trade.
+	ageQueueSize = 256
+	// ageSearchers is the number of concurrent background searches.
+	ageSearchers = 2
+	// probeAddress is the account whose historical balance Probe reads.
+	probeAddress = "0x0000000000000000000000000000000000000000"
+)
+
+// ErrNoActivity is returned when a wallet has no transactions or balance.
+var ErrNoActivity = errors.New("no on-chain activity found")
+
+// Activity is the block where a wallet first had a transaction or balance.
+type Activity struct {
+	Block uint64
+	At    time.Time
+}
+
+// ageEntry is a cached search result with its expiry time.
+type ageEntry struct {
+	activity  Activity
+	found     bool // false caches ErrNoActivity
+	expiresAt time.Time
+}
+
+// WalletAger finds when a wallet was first active by binary searching
+// historical blocks, so it needs an archive endpoint. A wallet counts as
+// active at a block once it has a nonce, a native balance or a USDC balance
+// there. An account can only empty its native balance by sending, so the
+// search holds unless every token was pulled out by an approved spender.
+//
+// A search makes dozens of calls, so workers use Lookup, which answers from
+// the cache and leaves misses to background searches started by Start.
+type WalletAger struct {
+	client   *Client
+	breaker  *CircuitBreaker
+	observer Observer
+	ttl      time.Duration
+	queue    chan string
+
+	mu      sync.RWMutex
+	entries map[string]ageEntry
+	pending map[string]bool // queued or being searched
+}
+
+// NewWalletAger creates a WalletAger. It has its own circuit breaker so
+// endpoints without historical state do not pause nonce lookups.
+func NewWalletAger(client *Client, cacheTTL time.Duration, breakerThreshold int, breakerCooldown time.Duration) *WalletAger {
+	return &WalletAger{
+		client:  client,
+		breaker: NewCircuitBreaker(breakerThreshold, breakerCooldown),
+		ttl:     cacheTTL,
+		queue:   make(chan string, ageQueueSize),
+		entries: make(map[string]ageEntry),
+		pending: make(map[string]bool),
+	}
+}
+
+// NewWalletAgerFromConfig creates a WalletAger on the nonce endpoints.
+// Returns nil if wallet age lookups are disabled.
+func NewWalletAgerFromConfig(cfg *config.Config) *WalletAger {
+	if !cfg.WalletAgeEnabled {
+		return nil
+	}
+	client := NewClient(cfg.RPCTimeout,
+		AlchemyEndpoint(cfg.AlchemyURL, cfg.AlchemyAPIKey),
+		Endpoint{Name: "fallback", URL: cfg.FallbackRPCURL},
+	)
+	return NewWalletAger(client, DefaultAgeCacheTTL, cfg.RPCBreakerThreshold, cfg.RPCBreakerCooldown)
+}
+
+// Probe checks that the endpoints serve historical state by reading a
+// balance at block 1, which non-archive nodes have pruned.
+func (a *WalletAger) Probe(ctx context.Context) error {
+	if _, err := a.client.GetBalance(ctx, probeAddress, BlockTag(1)); err != nil {
+		return fmt.Errorf("archive probe failed: %w", err)
+	}
+	return nil
+}
+
+// SetObserver sets the observer notified of every RPC lookup.
+func (a *WalletAger) SetObserver(o Observer) {
+	a.observer = o
+}
+
+// Start runs the background searches for Lookup until ctx is cancelled.
+func (a *WalletAger) Start(ctx context.Context) {
+	for i := 0; i < ageSearchers; i++ {
+		go a.run(ctx)
+	}
+}
+
+// Lookup returns the cached first activity of address without blocking.
+// On a miss it queues a background search and reports false; a later trade
+// by the same wallet gets the result.
+func (a *WalletAger) Lookup(address string) (Activity, bool) {
+	address = normalizeAddress(address)
+	if entry, ok := a.cached(address); ok {
+		return entry.activity, entry.found
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.pending[address] {
+		return Activity{}, false
+	}
+	select {
+	case a.queue <- address:
+		a.pending[address] = true
+	default:
+		slog.Debug("wallet_age_queue_full", "wallet", address)
+	}
+	return Activity{}, false
+}
+
+// run searches queued wallets until ctx is cancelled.
+func (a *WalletAger) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case address := <-a.queue:
+			if _, err := a.FirstActivity(ctx, address); err != nil && !errors.Is(err, ErrNoActivity) {
+				slog.Debug("wallet_age_failed", "wallet", address, "error", err)
+			}
+			a.mu.Lock()
+			delete(a.pending, address)
+			a.mu.Unlock()
+		}
+	}
+}
+
+// FirstActivity returns the block and time address was first active,
+// searching synchronously on a cache miss. Returns ErrNoActivity, also
+// cached, if the wallet has no transactions or balance.
+func (a *WalletAger) FirstActivity(ctx context.Context, address string) (Activity, error) {
+	address = normalizeAddress(address)
+
+	if entry, ok := a.cached(address); ok {
+		if !entry.found {
+			return Activity{}, ErrNoActivity
+		}
+		return entry.activity, nil
+	}
+
+	if !a.breaker.Allow() {
+		return Activity{}, ErrCircuitOpen
+	}
+
+	searchCtx, cancel := context.WithTimeout(ctx, ageLookupTimeout)
+	defer cancel()
+
+	activity, err := a.search(searchCtx, address)
+	if errors.Is(err, ErrNoActivity) {
+		a.breaker.Success()
+		a.store(address, ageEntry{expiresAt: time.Now().Add(NoActivityRetryAfter)})
+		return Activity{}, err
+	}
+	if err != nil {
+		if ctx.Err() == nil && a.breaker.Failure() {
+			slog.Error("wallet_age_circuit_open", "error", err)
+		}
+		return Activity{}, err
+	}
+	a.breaker.Success()
+	a.store(address, ageEntry{activity: activity, found: true, expiresAt: time.Now().Add(a.ttl)})
+
+	slog.Debug("wallet_age_found", "wallet", address, "block", activity.Block, "first_active", activity.At)
+	return activity, nil
+}
+
+// Cleanup removes expired cache entries.
+func (a *WalletAger) Cleanup() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := time.Now()
+	for addr, entry := range a.entries {
+		if now.After(entry.expiresAt) {
+			delete(a.entries, addr)
+		}
+	}
+}
+
+// cached returns the unexpired cache entry of a normalized address.
+func (a *WalletAger) cached(address string) (ageEntry, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	entry, ok := a.entries[address]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return ageEntry{}, false
+	}
+	return entry, true
+}
+
+// store caches a search result for a normalized address.
+func (a *WalletAger) store(address string, entry ageEntry) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.entries[address] = entry
+}
+
+// search binary searches for the first block where address is active.
+func (a *WalletAger) search(ctx context.Context, address string) (Activity, error) {
+	var latest uint64
+	err := a.observe(func() (err error) {
+		latest, err = a.client.BlockNumber(ctx)
+		return err
+	})
+	if err != nil {
+		return Activity{}, err
+	}
+
+	active, err := a.activeAt(ctx, address, latest)
+	if err != nil {
+		return Activity{}, err
+	}
+	if !active {
+		return Activity{}, ErrNoActivity
+	}
+
+	lo, hi := uint64(0), latest
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		active, err := a.activeAt(ctx, address, mid)
+		if err != nil {
+			return Activity{}, err
+		}
+		if active {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+
+	var at time.Time
+	err = a.observe(func() (err error) {
+		at, err = a.client.GetBlockTime(ctx, lo)
+		return err
+	})
+	if err != nil {
+		return Activity{}, err
+	}
+	return Activity{Block: lo, At: at}, nil
+}
+
+// activeAt reports whether address had a nonce or balance at block.
+func (a *WalletAger) activeAt(ctx context.Context, address string, block uint64) (bool, error) {
+	tag := BlockTag(block)
+
+	var nonce int
+	err := a.observe(func() (err error) {
+		nonce, err = a.client.GetTransactionCount(ctx, address, tag)
+		return err
+	})
+	if err != nil || nonce > 0 {
+		return nonce > 0, err
+	}
+
+	var funded bool
+	err = a.observe(func() error {
+		balance, err := a.client.GetBalance(ctx, address, tag)
+		funded = err == nil && balance.Sign() > 0
+		return err
+	})
+	if err != nil || funded {
+		return funded, err
+	}
+
+	err = a.observe(func() error {
+		balance, err := a.client.TokenBalance(ctx, USDCBridged, address, tag)
+		funded = err == nil && balance.Sign() > 0
+		return err
+	})
+	return funded, err
+}
+
+// observe runs one RPC call and reports its latency and outcome.
+func (a *WalletAger) observe(call func() error) error {
+	start := time.Now()
+	err := call()
+	if a.observer != nil {
+		a.observer.ObserveRPC(time.Since(start), err == nil)
+	}
+	return err
+}
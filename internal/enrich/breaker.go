@@ -0,0 +1,56 @@
+package enrich
+
+import (
+	"sync"
+	"time"
+)
+
+// CircuitBreaker pauses RPC calls after too many consecutive failures.
+type CircuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+}
+
+// NewCircuitBreaker creates a breaker that opens after threshold consecutive
+// failures and stays open for cooldown.
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	if threshold < 1 {
+		threshold = 1
+	}
+	return &CircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+	}
+}
+
+// Allow reports whether a call may be attempted.
+func (b *CircuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !time.Now().Before(b.openUntil)
+}
+
+// Success records a successful call and resets the failure count.
+func (b *CircuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+}
+
+// Failure records a failed call and returns true if the breaker just opened.
+func (b *CircuitBreaker) Failure() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.failures < b.threshold {
+		return false
+	}
+
+	b.failures = 0
+	b.openUntil = time.Now().Add(b.cooldown)
+	return true
+}
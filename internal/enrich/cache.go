@@ -0,0 +1,77 @@
+package enrich
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// cacheEntry is a cached nonce with its expiry time.
+type cacheEntry struct {
+	nonce     int
+	expiresAt time.Time
+}
+
+// NonceCache caches wallet nonces for a fixed TTL.
+type NonceCache struct {
+	mu      sync.RWMutex
+	entries map[string]cacheEntry
+	ttl     time.Duration
+}
+
+// NewNonceCache creates a new NonceCache with the specified TTL.
+func NewNonceCache(ttl time.Duration) *NonceCache {
+	return &NonceCache{
+		entries: make(map[string]cacheEntry),
+		ttl:     ttl,
+	}
+}
+
+// Get returns the cached nonce for address if present and not expired.
+func (c *NonceCache) Get(address string) (int, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.entries[normalizeAddress(address)]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return 0, false
+	}
+	return entry.nonce, true
+}
+
+// Set stores the nonce for address.
+func (c *NonceCache) Set(address string, nonce int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[normalizeAddress(address)] = cacheEntry{
+		nonce:     nonce,
+		expiresAt: time.Now().Add(c.ttl),
+	}
+}
+
+// Len returns the number of cached entries (including expired ones not yet cleaned up).
+func (c *NonceCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.entries)
+}
+
+// Cleanup removes expired entries.
+// Should be called periodically to prevent memory leaks.
+func (c *NonceCache) Cleanup() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for addr, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, addr)
+		}
+	}
+}
+
+// normalizeAddress lower-cases an address so checksummed and plain forms share a key.
+func normalizeAddress(address string) string {
+	return strings.ToLower(address)
+}
@@ -0,0 +1,339 @@
+package enrich
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/polyinsider/engine/internal/store"
+)
+
+// rpcHandler answers a single JSON-RPC method call for the stand-in server.
+type rpcHandler func(method string, params []json.RawMessage) (interface{}, *RPCError)
+
+// newRPCServer starts a local JSON-RPC stand-in and counts the requests it serves.
+func newRPCServer(t *testing.T, handler rpcHandler) (*httptest.Server, *atomic.Int64) {
+	t.Helper()
+	var calls atomic.Int64
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+
+		var req struct {
+			ID     uint64            `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		result, rpcErr := handler(req.Method, req.Params)
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		if rpcErr != nil {
+			resp["error"] = rpcErr
+		} else {
+			resp["result"] = result
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &calls
+}
+
+// failingServer returns a server that always responds with HTTP 500.
+func failingServer(t *testing.T) (*httptest.Server, *atomic.Int64) {
+	t.Helper()
+	var calls atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func TestNonceFallback(t *testing.T) {
+	primary, primaryCalls := failingServer(t)
+	fallback, fallbackCalls := newRPCServer(t, func(method string, params []json.RawMessage) (interface{}, *RPCError) {
+		if method != "eth_getTransactionCount" {
+			return nil, &RPCError{Code: -32601, Message: "method not found"}
+		}
+		return "0x3", nil
+	})
+
+	client := NewClient(time.Second,
+		Endpoint{Name: "alchemy", URL: primary.URL},
+		Endpoint{Name: "fallback", URL: fallback.URL},
+	)
+	e := NewEnricher(client, time.Minute, 5, time.Minute)
+
+	nonce, err := e.Nonce(context.Background(), "0xABC")
+	if err != nil {
+		t.Fatalf("Nonce returned error: %v", err)
+	}
+	if nonce != 3 {
+		t.Errorf("Expected nonce 3, got %d", nonce)
+	}
+	if primaryCalls.Load() != 1 || fallbackCalls.Load() != 1 {
+		t.Errorf("Expected 1 primary and 1 fallback call, got %d and %d", primaryCalls.Load(), fallbackCalls.Load())
+	}
+
+	// Cached lookup (case-insensitive) should not hit the network
+	if _, err := e.Nonce(context.Background(), "0xabc"); err != nil {
+		t.Fatalf("cached Nonce returned error: %v", err)
+	}
+	if fallbackCalls.Load() != 1 {
+		t.Errorf("Expected cache hit, got %d fallback calls", fallbackCalls.Load())
+	}
+}
+
+func TestNonceCircuitBreaker(t *testing.T) {
+	srv, calls := failingServer(t)
+	client := NewClient(time.Second, Endpoint{Name: "alchemy", URL: srv.URL})
+	e := NewEnricher(client, time.Minute, 3, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		if _, err := e.Nonce(context.Background(), "0xabc"); err == nil {
+			t.Fatalf("Expected error on call %d", i)
+		}
+	}
+
+	_, err := e.Nonce(context.Background(), "0xabc")
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen, got %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("Expected 3 calls before breaker opened, got %d", calls.Load())
+	}
+}
+
+func TestFundingTracerClusters(t *testing.T) {
+	const funder = "0x00000000000000000000000000000000000000f1"
+	transfers := map[string][]map[string]interface{}{
+		"0x00000000000000000000000000000000000000a1": {{
+			"blockNum": "0x10", "hash": "0xt1", "from": "0x00000000000000000000000000000000000000b0",
+			"category": "erc20", "asset": "WETH",
+			"rawContract": map[string]string{"address": "0x7ceb23fd6bc0add59e62ac25578270cff1b9f619"},
+		}, {
+			"blockNum": "0x11", "hash": "0xt2", "from": funder, "category": "erc20", "asset": "USDC",
+			"rawContract": map[string]string{"address": USDCBridged},
+			"metadata":    map[string]string{"blockTimestamp": "2026-03-01T12:00:00.000Z"},
+		}},
+		"0x00000000000000000000000000000000000000a2": {{
+			"blockNum": "0x20", "hash": "0xt3", "from": funder, "category": "external", "asset": "MATIC",
+		}},
+	}
+	srv, calls := newRPCServer(t, func(method string, params []json.RawMessage) (interface{}, *RPCError) {
+		if method != "alchemy_getAssetTransfers" {
+			return nil, &RPCError{Code: -32601, Message: "method not found"}
+		}
+		var filter struct {
+			ToAddress string `json:"toAddress"`
+		}
+		json.Unmarshal(params[0], &filter)
+		return map[string]interface{}{"transfers": transfers[filter.ToAddress]}, nil
+	})
+
+	tracer := NewFundingTracer(NewClient(time.Second, Endpoint{Name: "stand-in", URL: srv.URL}), 5, time.Minute)
+	ctx := context.Background()
+
+	f, size, err := tracer.Trace(ctx, "0x00000000000000000000000000000000000000A1")
+	if err != nil {
+		t.Fatalf("Trace returned error: %v", err)
+	}
+	if f.Funder != funder || f.Asset != "USDC" || f.Block != 0x11 || size != 1 {
+		t.Errorf("Unexpected first funding: %+v, cluster %d", f, size)
+	}
+
+	if _, size, err = tracer.Trace(ctx, "0x00000000000000000000000000000000000000a2"); err != nil || size != 2 {
+		t.Errorf("Expected second wallet to join a cluster of 2, got %d, %v", size, err)
+	}
+	if _, _, err := tracer.Trace(ctx, "0x00000000000000000000000000000000000000a3"); !errors.Is(err, ErrNoFunding) {
+		t.Errorf("Expected ErrNoFunding for an unfunded wallet, got %v", err)
+	}
+
+	// Known fundings and misses are served without another lookup
+	before := calls.Load()
+	tracer.Trace(ctx, "0x00000000000000000000000000000000000000a1")
+	tracer.Trace(ctx, "0x00000000000000000000000000000000000000a3")
+	if calls.Load() != before {
+		t.Errorf("Expected cached traces, got %d new calls", calls.Load()-before)
+	}
+	if got := tracer.ClusterSize(funder); got != 2 {
+		t.Errorf("Expected cluster size 2, got %d", got)
+	}
+}
+
+func TestFundingLookupInBackground(t *testing.T) {
+	const wallet = "0x00000000000000000000000000000000000000a1"
+	saved := make(chan store.Funding, 1)
+	srv, calls := newRPCServer(t, func(method string, params []json.RawMessage) (interface{}, *RPCError) {
+		return map[string]interface{}{"transfers": []map[string]interface{}{{
+			"blockNum": "0x10", "hash": "0xt1", "from": "0x00000000000000000000000000000000000000f1",
+			"category": "external", "asset": "MATIC",
+		}}}, nil
+	})
+
+	tracer := NewFundingTracer(NewClient(time.Second, Endpoint{Name: "stand-in", URL: srv.URL}), 5, time.Minute)
+	tracer.SetStore(fundingRecorder(saved))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tracer.Start(ctx)
+
+	// A miss returns at once and is traced and stored in the background
+	if _, _, ok := tracer.Lookup(wallet); ok {
+		t.Fatal("Expected a miss on the first lookup")
+	}
+	select {
+	case f := <-saved:
+		if f.Wallet != wallet {
+			t.Errorf("Unexpected stored funding %+v", f)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Background trace did not finish")
+	}
+
+	before := calls.Load()
+	if f, size, ok := tracer.Lookup(wallet); !ok || f.Funder == "" || size != 1 {
+		t.Errorf("Expected the traced funding, got %+v, %d, %v", f, size, ok)
+	}
+	if calls.Load() != before {
+		t.Errorf("Expected a cached funding, got %d new calls", calls.Load()-before)
+	}
+}
+
+// fundingRecorder is a FundingStore that forwards saved fundings.
+type fundingRecorder chan store.Funding
+
+func (r fundingRecorder) SaveFunding(f store.Funding) error {
+	r <- f
+	return nil
+}
+
+func TestWalletAgeBinarySearch(t *testing.T) {
+	const (
+		latest     = 5000
+		funded     = 1234 // first block with a native balance
+		firstNonce = 3000 // first block with a sent transaction
+		genesis    = 1_700_000_000
+	)
+	blockOf := func(params []json.RawMessage, i int) uint64 {
+		var tag string
+		json.Unmarshal(params[i], &tag)
+		n, _ := parseHexUint(tag)
+		return n
+	}
+	quantity := func(active bool) string {
+		if active {
+			return "0x1"
+		}
+		return "0x0"
+	}
+	srv, calls := newRPCServer(t, func(method string, params []json.RawMessage) (interface{}, *RPCError) {
+		switch method {
+		case "eth_blockNumber":
+			return BlockTag(latest), nil
+		case "eth_getTransactionCount":
+			return quantity(blockOf(params, 1) >= firstNonce), nil
+		case "eth_getBalance":
+			return quantity(blockOf(params, 1) >= funded), nil
+		case "eth_call":
+			return "0x", nil
+		case "eth_getBlockByNumber":
+			return map[string]string{"timestamp": BlockTag(genesis + 2*blockOf(params, 0))}, nil
+		}
+		return nil, &RPCError{Code: -32601, Message: "method not found"}
+	})
+
+	ager := NewWalletAger(NewClient(time.Second, Endpoint{Name: "stand-in", URL: srv.URL}), time.Hour, 5, time.Minute)
+
+	activity, err := ager.FirstActivity(context.Background(), "0xABC")
+	if err != nil {
+		t.Fatalf("FirstActivity returned error: %v", err)
+	}
+	if activity.Block != funded || !activity.At.Equal(time.Unix(genesis+2*funded, 0)) {
+		t.Errorf("Expected first activity at block %d, got %+v", funded, activity)
+	}
+
+	before := calls.Load()
+	if _, err := ager.FirstActivity(context.Background(), "0xabc"); err != nil || calls.Load() != before {
+		t.Errorf("Expected a cached age, got %v after %d calls", err, calls.Load()-before)
+	}
+	if cached, ok := ager.Lookup("0xAbc"); !ok || cached != activity {
+		t.Errorf("Expected Lookup to serve the cached activity, got %+v, %v", cached, ok)
+	}
+}
+
+func TestWalletAgeLookupInBackground(t *testing.T) {
+	srv, calls := newRPCServer(t, func(method string, params []json.RawMessage) (interface{}, *RPCError) {
+		switch method {
+		case "eth_blockNumber":
+			return "0x64", nil
+		case "eth_getTransactionCount", "eth_getBalance":
+			return "0x0", nil
+		case "eth_call":
+			return "0x", nil
+		}
+		return nil, &RPCError{Code: -32601, Message: "method not found"}
+	})
+
+	ager := NewWalletAger(NewClient(time.Second, Endpoint{Name: "stand-in", URL: srv.URL}), time.Hour, 5, time.Minute)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ager.Start(ctx)
+
+	// A miss returns at once and is searched in the background
+	if _, ok := ager.Lookup("0xempty"); ok {
+		t.Fatal("Expected a miss on the first lookup")
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, ok := ager.cached("0xempty"); ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Background search did not finish")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// The empty wallet is cached and not searched again
+	before := calls.Load()
+	ager.Lookup("0xempty")
+	if _, err := ager.FirstActivity(ctx, "0xEMPTY"); !errors.Is(err, ErrNoActivity) || calls.Load() != before {
+		t.Errorf("Expected a cached ErrNoActivity, got %v after %d calls", err, calls.Load()-before)
+	}
+}
+
+func TestWalletAgeProbe(t *testing.T) {
+	// A pruned node serves the latest state only
+	pruned, _ := newRPCServer(t, func(method string, params []json.RawMessage) (interface{}, *RPCError) {
+		var block string
+		json.Unmarshal(params[1], &block)
+		if block != "latest" {
+			return nil, &RPCError{Code: -32000, Message: "missing trie node"}
+		}
+		return "0x0", nil
+	})
+	archive, _ := newRPCServer(t, func(method string, params []json.RawMessage) (interface{}, *RPCError) {
+		return "0x0", nil
+	})
+
+	ctx := context.Background()
+	ager := NewWalletAger(NewClient(time.Second, Endpoint{Name: "pruned", URL: pruned.URL}), time.Hour, 5, time.Minute)
+	if err := ager.Probe(ctx); err == nil {
+		t.Error("Expected the probe to fail without historical state")
+	}
+	ager = NewWalletAger(NewClient(time.Second, Endpoint{Name: "archive", URL: archive.URL}), time.Hour, 5, time.Minute)
+	if err := ager.Probe(ctx); err != nil {
+		t.Errorf("Expected the probe to pass on an archive node, got %v", err)
+	}
+}
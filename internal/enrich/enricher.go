@@ -0,0 +1,82 @@
+package enrich
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/polyinsider/engine/internal/config"
+)
+
+// ErrCircuitOpen is returned when enrichment is paused after repeated RPC failures.
+var ErrCircuitOpen = errors.New("rpc circuit breaker open")
+
+// Observer receives the latency and outcome of each RPC lookup.
+type Observer interface {
+	ObserveRPC(latency time.Duration, success bool)
+}
+
+// Enricher looks up wallet nonces with caching and a circuit breaker.
+type Enricher struct {
+	client   *Client
+	cache    *NonceCache
+	breaker  *CircuitBreaker
+	observer Observer
+}
+
+// NewEnricher creates a new Enricher.
+func NewEnricher(client *Client, cacheTTL time.Duration, breakerThreshold int, breakerCooldown time.Duration) *Enricher {
+	return &Enricher{
+		client:  client,
+		cache:   NewNonceCache(cacheTTL),
+		breaker: NewCircuitBreaker(breakerThreshold, breakerCooldown),
+	}
+}
+
+// NewEnricherFromConfig creates an Enricher using Alchemy as primary and the
+// public RPC as fallback.
+func NewEnricherFromConfig(cfg *config.Config) *Enricher {
+	client := NewClient(cfg.RPCTimeout,
+		AlchemyEndpoint(cfg.AlchemyURL, cfg.AlchemyAPIKey),
+		Endpoint{Name: "fallback", URL: cfg.FallbackRPCURL},
+	)
+	return NewEnricher(client, cfg.NonceCacheTTL, cfg.RPCBreakerThreshold, cfg.RPCBreakerCooldown)
+}
+
+// SetObserver sets the observer notified of every RPC lookup.
+func (e *Enricher) SetObserver(o Observer) {
+	e.observer = o
+}
+
+// Nonce returns the current transaction count for address.
+func (e *Enricher) Nonce(ctx context.Context, address string) (int, error) {
+	if nonce, ok := e.cache.Get(address); ok {
+		return nonce, nil
+	}
+
+	if !e.breaker.Allow() {
+		return 0, ErrCircuitOpen
+	}
+
+	start := time.Now()
+	nonce, err := e.client.GetTransactionCount(ctx, address, "latest")
+	if e.observer != nil {
+		e.observer.ObserveRPC(time.Since(start), err == nil)
+	}
+	if err != nil {
+		if ctx.Err() == nil && e.breaker.Failure() {
+			slog.Error("rpc_circuit_open", "error", err)
+		}
+		return 0, err
+	}
+
+	e.breaker.Success()
+	e.cache.Set(address, nonce)
+	return nonce, nil
+}
+
+// Cleanup removes expired cache entries.
+func (e *Enricher) Cleanup() {
+	e.cache.Cleanup()
+}
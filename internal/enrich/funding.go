@@ -0,0 +1,303 @@
+package enrich
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/polyinsider/engine/internal/config"
+	"github.com/polyinsider/engine/internal/store"
+)
+
+// Polygon USDC contracts whose transfers count as funding.
+const (
+	USDCBridged = "0x2791bca1f2de4661ed88a30c99a7a9449aa84174" // USDC.e, used by Polymarket
+	USDCNative  = "0x3c499c542cef5e3811e1192ce70d8cc03d5c3359"
+)
+
+const (
+	// fundingLookback is how many of a wallet's earliest inbound transfers
+	// are scanned for the first MATIC or USDC one.
+	fundingLookback = 20
+	// FundingRetryAfter is how long a wallet with no funding found is not traced again.
+	FundingRetryAfter = time.Hour
+	// fundingQueueSize bounds the wallets waiting for a background trace;
+	// wallets beyond it are dropped and requested again by a later trade.
+	fundingQueueSize = 256
+	// fundingTracers is the number of concurrent background traces.
+	fundingTracers = 2
+)
+
+// ErrNoFunding is returned when a wallet has no inbound MATIC or USDC transfer.
+var ErrNoFunding = errors.New("no funding transfer found")
+
+// FundingStore persists traced fundings and attaches them to the wallet's
+// stored suspects.
+type FundingStore interface {
+	SaveFunding(f store.Funding) error
+}
+
+// FundingTracer finds the address that first funded a wallet and groups
+// wallets sharing a funder into clusters. A wallet's first funder never
+// changes, so traced fundings are kept for the life of the process.
+//
+// Workers use Lookup, which answers from memory and leaves unknown wallets
+// to background traces started by Start; the store attaches those fundings
+// to suspects already written.
+type FundingTracer struct {
+	client   *Client
+	breaker  *CircuitBreaker
+	observer Observer
+	store    FundingStore
+	queue    chan string
+
+	mu       sync.Mutex
+	fundings map[string]store.Funding   // wallet -> funding
+	misses   map[string]time.Time       // wallet -> when a trace found nothing
+	clusters map[string]map[string]bool // funder -> wallets
+	pending  map[string]bool            // queued or being traced
+}
+
+// NewFundingTracer creates a tracer that queries client. It has its own
+// circuit breaker so a failing transfers API does not pause nonce lookups.
+func NewFundingTracer(client *Client, breakerThreshold int, breakerCooldown time.Duration) *FundingTracer {
+	return &FundingTracer{
+		client:   client,
+		breaker:  NewCircuitBreaker(breakerThreshold, breakerCooldown),
+		queue:    make(chan string, fundingQueueSize),
+		fundings: make(map[string]store.Funding),
+		misses:   make(map[string]time.Time),
+		clusters: make(map[string]map[string]bool),
+		pending:  make(map[string]bool),
+	}
+}
+
+// NewFundingTracerFromConfig creates a tracer against FUNDING_RPC_URL, or
+// Alchemy when it is unset. Returns nil if tracing is disabled or no
+// endpoint is configured.
+func NewFundingTracerFromConfig(cfg *config.Config) *FundingTracer {
+	if !cfg.FundingTraceEnabled {
+		return nil
+	}
+
+	endpoint := Endpoint{Name: "funding", URL: cfg.FundingRPCURL}
+	if endpoint.URL == "" {
+		endpoint = AlchemyEndpoint(cfg.AlchemyURL, cfg.AlchemyAPIKey)
+	}
+	if endpoint.URL == "" {
+		slog.Info("funding_tracer_disabled", "reason", "no alchemy key or FUNDING_RPC_URL")
+		return nil
+	}
+
+	client := NewClient(cfg.RPCTimeout, endpoint)
+	return NewFundingTracer(client, cfg.RPCBreakerThreshold, cfg.RPCBreakerCooldown)
+}
+
+// SetObserver sets the observer notified of every RPC lookup.
+func (t *FundingTracer) SetObserver(o Observer) {
+	t.observer = o
+}
+
+// SetStore sets where newly traced fundings are persisted.
+func (t *FundingTracer) SetStore(s FundingStore) {
+	t.store = s
+}
+
+// Load adds previously persisted fundings, rebuilding their clusters.
+func (t *FundingTracer) Load(fundings []store.Funding) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, f := range fundings {
+		t.addLocked(f)
+	}
+}
+
+// Start runs the background traces for Lookup until ctx is cancelled.
+func (t *FundingTracer) Start(ctx context.Context) {
+	for i := 0; i < fundingTracers; i++ {
+		go t.run(ctx)
+	}
+}
+
+// Lookup returns the known funding of wallet and its cluster size without
+// blocking. On a miss it queues a background trace and reports false.
+func (t *FundingTracer) Lookup(wallet string) (store.Funding, int, bool) {
+	wallet = normalizeAddress(wallet)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if f, ok := t.fundings[wallet]; ok {
+		return f, len(t.clusters[f.Funder]), true
+	}
+	if missed, ok := t.misses[wallet]; ok && time.Since(missed) < FundingRetryAfter {
+		return store.Funding{}, 0, false
+	}
+	if t.pending[wallet] {
+		return store.Funding{}, 0, false
+	}
+	select {
+	case t.queue <- wallet:
+		t.pending[wallet] = true
+	default:
+		slog.Debug("funding_queue_full", "wallet", wallet)
+	}
+	return store.Funding{}, 0, false
+}
+
+// run traces queued wallets until ctx is cancelled.
+func (t *FundingTracer) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case wallet := <-t.queue:
+			if _, _, err := t.Trace(ctx, wallet); err != nil && !errors.Is(err, ErrNoFunding) {
+				slog.Debug("funding_trace_failed", "wallet", wallet, "error", err)
+			}
+			t.mu.Lock()
+			delete(t.pending, wallet)
+			t.mu.Unlock()
+		}
+	}
+}
+
+// Trace returns the funding of wallet and the number of wallets known to
+// share its funder, including wallet, tracing synchronously on a miss.
+// Returns ErrNoFunding if the wallet has no inbound MATIC or USDC transfer.
+func (t *FundingTracer) Trace(ctx context.Context, wallet string) (store.Funding, int, error) {
+	wallet = normalizeAddress(wallet)
+
+	t.mu.Lock()
+	if f, ok := t.fundings[wallet]; ok {
+		size := len(t.clusters[f.Funder])
+		t.mu.Unlock()
+		return f, size, nil
+	}
+	if missed, ok := t.misses[wallet]; ok && time.Since(missed) < FundingRetryAfter {
+		t.mu.Unlock()
+		return store.Funding{}, 0, ErrNoFunding
+	}
+	t.mu.Unlock()
+
+	if !t.breaker.Allow() {
+		return store.Funding{}, 0, ErrCircuitOpen
+	}
+
+	start := time.Now()
+	transfers, err := t.client.GetAssetTransfers(ctx, TransferFilter{
+		ToAddress:         wallet,
+		Categories:        []string{TransferExternal, TransferERC20},
+		ContractAddresses: []string{USDCBridged, USDCNative},
+		MaxCount:          fundingLookback,
+	})
+	if t.observer != nil {
+		t.observer.ObserveRPC(time.Since(start), err == nil)
+	}
+	if err != nil {
+		if ctx.Err() == nil && t.breaker.Failure() {
+			slog.Error("funding_circuit_open", "error", err)
+		}
+		return store.Funding{}, 0, err
+	}
+	t.breaker.Success()
+
+	f, found := firstFunding(wallet, transfers)
+
+	t.mu.Lock()
+	if !found {
+		t.misses[wallet] = time.Now()
+		t.mu.Unlock()
+		return store.Funding{}, 0, ErrNoFunding
+	}
+	t.addLocked(f)
+	size := len(t.clusters[f.Funder])
+	t.mu.Unlock()
+
+	if t.store != nil {
+		if err := t.store.SaveFunding(f); err != nil {
+			slog.Warn("funding_save_failed", "wallet", wallet, "error", err)
+		}
+	}
+	slog.Debug("funding_traced", "wallet", wallet, "funder", f.Funder, "asset", f.Asset, "cluster_size", size)
+	return f, size, nil
+}
+
+// ClusterSize returns the number of wallets known to be funded by funder.
+func (t *FundingTracer) ClusterSize(funder string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.clusters[normalizeAddress(funder)])
+}
+
+// Stats returns the number of traced wallets and distinct funders.
+func (t *FundingTracer) Stats() (wallets, funders int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.fundings), len(t.clusters)
+}
+
+// Cleanup forgets expired misses so those wallets are traced again.
+func (t *FundingTracer) Cleanup() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for wallet, missed := range t.misses {
+		if time.Since(missed) >= FundingRetryAfter {
+			delete(t.misses, wallet)
+		}
+	}
+}
+
+// addLocked records f and adds its wallet to the funder's cluster.
+// Must be called with lock held.
+func (t *FundingTracer) addLocked(f store.Funding) {
+	f.Wallet = normalizeAddress(f.Wallet)
+	f.Funder = normalizeAddress(f.Funder)
+
+	t.fundings[f.Wallet] = f
+	delete(t.misses, f.Wallet)
+
+	cluster, ok := t.clusters[f.Funder]
+	if !ok {
+		cluster = make(map[string]bool)
+		t.clusters[f.Funder] = cluster
+	}
+	cluster[f.Wallet] = true
+}
+
+// firstFunding returns the earliest native or USDC transfer into wallet.
+// Transfers are in block order; other tokens are skipped in case the
+// endpoint ignores the contract filter.
+func firstFunding(wallet string, transfers []AssetTransfer) (store.Funding, bool) {
+	for _, tr := range transfers {
+		if tr.From == "" || normalizeAddress(tr.From) == wallet {
+			continue
+		}
+		switch tr.Category {
+		case TransferExternal:
+		case TransferERC20:
+			contract := normalizeAddress(tr.RawContract.Address)
+			if contract != USDCBridged && contract != USDCNative {
+				continue
+			}
+		default:
+			continue
+		}
+
+		block, _ := parseHexUint(tr.BlockNum)
+		fundedAt, _ := time.Parse(time.RFC3339, tr.Metadata.BlockTimestamp)
+		return store.Funding{
+			Wallet:   wallet,
+			Funder:   normalizeAddress(tr.From),
+			Asset:    strings.ToUpper(tr.Asset),
+			TxHash:   tr.Hash,
+			Block:    block,
+			FundedAt: fundedAt,
+		}, true
+	}
+	return store.Funding{}, false
+}
@@ -0,0 +1,347 @@
+// Package enrich provides on-chain wallet enrichment via Polygon JSON-RPC.
+package enrich
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultRPCTimeout is the per-request timeout for RPC calls
+	DefaultRPCTimeout = 5 * time.Second
+	// SlowRPCThreshold is the latency above which calls are logged as slow
+	SlowRPCThreshold = 400 * time.Millisecond
+)
+
+// Endpoint is a named JSON-RPC endpoint.
+type Endpoint struct {
+	Name string
+	URL  string
+}
+
+// rpcRequest is a JSON-RPC 2.0 request body.
+type rpcRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      uint64        `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+// rpcResponse is a JSON-RPC 2.0 response body.
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      uint64          `json:"id"`
+	Result  json.RawMessage `json:"result"`
+	Error   *RPCError       `json:"error"`
+}
+
+// RPCError is an error object returned by a JSON-RPC endpoint.
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+// Client is a JSON-RPC client that tries each endpoint in order until one succeeds.
+type Client struct {
+	endpoints []Endpoint
+	http      *http.Client
+	nextID    atomic.Uint64
+}
+
+// NewClient creates a new Client. Endpoints are tried in the order given.
+func NewClient(timeout time.Duration, endpoints ...Endpoint) *Client {
+	if timeout == 0 {
+		timeout = DefaultRPCTimeout
+	}
+
+	var valid []Endpoint
+	for _, ep := range endpoints {
+		if ep.URL != "" {
+			valid = append(valid, ep)
+		}
+	}
+
+	return &Client{
+		endpoints: valid,
+		http:      &http.Client{Timeout: timeout},
+	}
+}
+
+// AlchemyEndpoint builds the Alchemy endpoint from its base URL and API key.
+// Returns an empty Endpoint if no key is configured.
+func AlchemyEndpoint(baseURL, apiKey string) Endpoint {
+	if apiKey == "" || baseURL == "" {
+		return Endpoint{}
+	}
+	return Endpoint{
+		Name: "alchemy",
+		URL:  strings.TrimSuffix(baseURL, "/") + "/" + apiKey,
+	}
+}
+
+// Call invokes method on each endpoint in turn and decodes the first successful result.
+func (c *Client) Call(ctx context.Context, method string, params []interface{}, result interface{}) error {
+	if len(c.endpoints) == 0 {
+		return fmt.Errorf("no rpc endpoints configured")
+	}
+
+	var lastErr error
+	for _, ep := range c.endpoints {
+		start := time.Now()
+		err := c.call(ctx, ep, method, params, result)
+		latency := time.Since(start)
+
+		if latency > SlowRPCThreshold {
+			slog.Warn("rpc_slow", "latency_ms", latency.Milliseconds(), "endpoint", ep.Name, "method", method)
+		}
+
+		if err == nil {
+			return nil
+		}
+
+		slog.Debug("rpc_call_failed", "endpoint", ep.Name, "method", method, "error", err)
+		lastErr = fmt.Errorf("%s: %w", ep.Name, err)
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	return lastErr
+}
+
+// call performs a single JSON-RPC request against one endpoint.
+func (c *Client) call(ctx context.Context, ep Endpoint, method string, params []interface{}, result interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+
+	body, err := json.Marshal(rpcRequest{
+		JSONRPC: "2.0",
+		ID:      c.nextID.Add(1),
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal request failed: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request failed: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+
+	var rpcResp rpcResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+		return fmt.Errorf("decode failed: %w", err)
+	}
+
+	if rpcResp.Error != nil {
+		return rpcResp.Error
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	if err := json.Unmarshal(rpcResp.Result, result); err != nil {
+		return fmt.Errorf("decode result failed: %w", err)
+	}
+
+	return nil
+}
+
+// GetTransactionCount returns the nonce of address at the given block tag (e.g. "latest").
+func (c *Client) GetTransactionCount(ctx context.Context, address, block string) (int, error) {
+	var hexCount string
+	if err := c.Call(ctx, "eth_getTransactionCount", []interface{}{address, block}, &hexCount); err != nil {
+		return 0, err
+	}
+
+	count, err := parseHexUint(hexCount)
+	if err != nil {
+		return 0, fmt.Errorf("invalid transaction count %q: %w", hexCount, err)
+	}
+
+	return int(count), nil
+}
+
+// BlockNumber returns the number of the latest block.
+func (c *Client) BlockNumber(ctx context.Context) (uint64, error) {
+	var hexNumber string
+	if err := c.Call(ctx, "eth_blockNumber", []interface{}{}, &hexNumber); err != nil {
+		return 0, err
+	}
+
+	number, err := parseHexUint(hexNumber)
+	if err != nil {
+		return 0, fmt.Errorf("invalid block number %q: %w", hexNumber, err)
+	}
+	return number, nil
+}
+
+// GetBlockTime returns the timestamp of block number.
+func (c *Client) GetBlockTime(ctx context.Context, number uint64) (time.Time, error) {
+	var block *struct {
+		Timestamp string `json:"timestamp"`
+	}
+	if err := c.Call(ctx, "eth_getBlockByNumber", []interface{}{BlockTag(number), false}, &block); err != nil {
+		return time.Time{}, err
+	}
+	if block == nil {
+		return time.Time{}, fmt.Errorf("block %d not found", number)
+	}
+
+	seconds, err := parseHexUint(block.Timestamp)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid block timestamp %q: %w", block.Timestamp, err)
+	}
+	return time.Unix(int64(seconds), 0).UTC(), nil
+}
+
+// GetBalance returns the native balance of address in wei at the given block tag.
+func (c *Client) GetBalance(ctx context.Context, address, block string) (*big.Int, error) {
+	var hexBalance string
+	if err := c.Call(ctx, "eth_getBalance", []interface{}{address, block}, &hexBalance); err != nil {
+		return nil, err
+	}
+
+	balance, err := parseHexBig(hexBalance)
+	if err != nil {
+		return nil, fmt.Errorf("invalid balance %q: %w", hexBalance, err)
+	}
+	return balance, nil
+}
+
+// TokenBalance returns the ERC-20 balance of owner in token at the given
+// block tag. A block before the token was deployed has a zero balance.
+func (c *Client) TokenBalance(ctx context.Context, token, owner, block string) (*big.Int, error) {
+	// balanceOf(address) with the owner left-padded to 32 bytes
+	data := "0x70a08231" + fmt.Sprintf("%064s", strings.TrimPrefix(normalizeAddress(owner), "0x"))
+	call := map[string]string{"to": token, "data": data}
+
+	var hexBalance string
+	if err := c.Call(ctx, "eth_call", []interface{}{call, block}, &hexBalance); err != nil {
+		return nil, err
+	}
+	if hexBalance == "0x" || hexBalance == "" {
+		return new(big.Int), nil
+	}
+
+	balance, err := parseHexBig(hexBalance)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token balance %q: %w", hexBalance, err)
+	}
+	return balance, nil
+}
+
+// BlockTag formats a block number as a JSON-RPC block parameter.
+func BlockTag(number uint64) string {
+	return fmt.Sprintf("0x%x", number)
+}
+
+// parseHexUint parses a 0x-prefixed hex quantity.
+func parseHexUint(s string) (uint64, error) {
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	if s == "" {
+		return 0, fmt.Errorf("empty quantity")
+	}
+	return strconv.ParseUint(s, 16, 64)
+}
+
+// parseHexBig parses a 0x-prefixed hex quantity of any size.
+func parseHexBig(s string) (*big.Int, error) {
+	digits := strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	if digits == "" {
+		return nil, fmt.Errorf("empty quantity")
+	}
+	n, ok := new(big.Int).SetString(digits, 16)
+	if !ok {
+		return nil, fmt.Errorf("not a hex quantity")
+	}
+	return n, nil
+}
+
+// Asset transfer categories of alchemy_getAssetTransfers.
+const (
+	TransferExternal = "external" // native MATIC/POL sent by an account
+	TransferERC20    = "erc20"
+)
+
+// TransferFilter selects transfers for GetAssetTransfers.
+type TransferFilter struct {
+	ToAddress         string
+	Categories        []string
+	ContractAddresses []string // token contracts; only applies to token categories
+	MaxCount          int
+}
+
+// AssetTransfer is one transfer returned by alchemy_getAssetTransfers.
+type AssetTransfer struct {
+	BlockNum    string   `json:"blockNum"` // hex
+	Hash        string   `json:"hash"`
+	From        string   `json:"from"`
+	To          string   `json:"to"`
+	Value       *float64 `json:"value"`
+	Asset       string   `json:"asset"`
+	Category    string   `json:"category"`
+	RawContract struct {
+		Address string `json:"address"`
+	} `json:"rawContract"`
+	Metadata struct {
+		BlockTimestamp string `json:"blockTimestamp"`
+	} `json:"metadata"`
+}
+
+// GetAssetTransfers returns the earliest transfers matching filter, oldest
+// first. It uses the Alchemy transfers API, which plain nodes do not serve.
+func (c *Client) GetAssetTransfers(ctx context.Context, filter TransferFilter) ([]AssetTransfer, error) {
+	params := map[string]interface{}{
+		"fromBlock":        "0x0",
+		"toBlock":          "latest",
+		"toAddress":        filter.ToAddress,
+		"category":         filter.Categories,
+		"order":            "asc",
+		"withMetadata":     true,
+		"excludeZeroValue": true,
+	}
+	if len(filter.ContractAddresses) > 0 {
+		params["contractAddresses"] = filter.ContractAddresses
+	}
+	if filter.MaxCount > 0 {
+		params["maxCount"] = fmt.Sprintf("0x%x", filter.MaxCount)
+	}
+
+	var result struct {
+		Transfers []AssetTransfer `json:"transfers"`
+	}
+	if err := c.Call(ctx, "alchemy_getAssetTransfers", []interface{}{params}, &result); err != nil {
+		return nil, err
+	}
+	return result.Transfers, nil
+}
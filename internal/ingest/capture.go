@@ -0,0 +1,184 @@
+package ingest
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DefaultCaptureMaxBytes is the uncompressed size at which a capture file is rotated.
+const DefaultCaptureMaxBytes = 100 << 20
+
+// CaptureRecord is one line of a capture file: either a raw WebSocket frame
+// or the set of markets fetched by discovery.
+type CaptureRecord struct {
+	Time    time.Time `json:"ts"`
+	Conn    string    `json:"conn,omitempty"`
+	Frame   string    `json:"frame,omitempty"`
+	Markets []Market  `json:"markets,omitempty"`
+}
+
+// FrameRecorder receives every raw frame read by a Listener.
+type FrameRecorder interface {
+	RecordFrame(conn string, data []byte, at time.Time)
+}
+
+// Capture writes raw frames and market snapshots to rotating gzip-compressed
+// NDJSON files, named capture-<start time>-<seq>.ndjson.gz. It is safe for
+// use by several listeners at once.
+type Capture struct {
+	dir      string
+	maxBytes int64
+
+	mu      sync.Mutex
+	file    *os.File
+	gz      *gzip.Writer
+	written int64    // uncompressed bytes in the current file
+	seq     int      // files opened so far
+	markets []Market // last market snapshot, repeated at the top of every file
+	failed  bool     // a write error was already logged
+}
+
+// NewCapture creates dir if needed and opens the first capture file.
+func NewCapture(dir string, maxBytes int64) (*Capture, error) {
+	if maxBytes <= 0 {
+		maxBytes = DefaultCaptureMaxBytes
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create capture dir: %w", err)
+	}
+
+	c := &Capture{dir: dir, maxBytes: maxBytes}
+	if err := c.rotate(time.Now()); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// RecordFrame writes a raw frame received on conn at the given time.
+func (c *Capture) RecordFrame(conn string, data []byte, at time.Time) {
+	c.write(CaptureRecord{Time: at, Conn: conn, Frame: string(data)})
+}
+
+// RecordMarkets writes a market snapshot so replay can rebuild the asset registry.
+func (c *Capture) RecordMarkets(markets []Market, at time.Time) {
+	c.mu.Lock()
+	c.markets = markets
+	c.mu.Unlock()
+
+	c.write(CaptureRecord{Time: at, Markets: markets})
+}
+
+// WrapFetcher returns a MarketFetcher that records every successful fetch.
+func (c *Capture) WrapFetcher(fetch MarketFetcher) MarketFetcher {
+	return func() ([]Market, error) {
+		markets, err := fetch()
+		if err == nil {
+			c.RecordMarkets(markets, time.Now())
+		}
+		return markets, err
+	}
+}
+
+// Close flushes and closes the current capture file.
+func (c *Capture) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closeFile()
+}
+
+// write appends one record, rotating first if the current file is full.
+func (c *Capture) write(record CaptureRecord) {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.gz == nil {
+		return
+	}
+
+	if c.written > 0 && c.written+int64(len(line)) > c.maxBytes {
+		if err := c.rotate(record.Time); err != nil {
+			c.logFailure(err)
+			return
+		}
+	}
+
+	if err := c.writeLine(line); err != nil {
+		c.logFailure(err)
+	}
+}
+
+// rotate closes the current file and opens the next one.
+// Must be called with lock held (or before the capture is shared).
+func (c *Capture) rotate(now time.Time) error {
+	if err := c.closeFile(); err != nil {
+		slog.Warn("capture_close_failed", "error", err)
+	}
+
+	c.seq++
+	name := fmt.Sprintf("capture-%s-%03d.ndjson.gz", now.UTC().Format("20060102T150405"), c.seq)
+	path := filepath.Join(c.dir, name)
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create capture file: %w", err)
+	}
+	c.file = file
+	c.gz = gzip.NewWriter(file)
+	c.written = 0
+
+	slog.Info("capture_file_opened", "path", path)
+
+	// Every file starts with the current markets so it can be replayed on its own
+	if c.markets != nil {
+		line, err := json.Marshal(CaptureRecord{Time: now, Markets: c.markets})
+		if err == nil {
+			return c.writeLine(append(line, '\n'))
+		}
+	}
+	return nil
+}
+
+// writeLine writes one encoded record. Must be called with lock held.
+func (c *Capture) writeLine(line []byte) error {
+	n, err := c.gz.Write(line)
+	c.written += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write capture record: %w", err)
+	}
+	return nil
+}
+
+// closeFile flushes and closes the current file. Must be called with lock held.
+func (c *Capture) closeFile() error {
+	if c.gz == nil {
+		return nil
+	}
+	gzErr := c.gz.Close()
+	fileErr := c.file.Close()
+	c.gz, c.file = nil, nil
+	if gzErr != nil {
+		return gzErr
+	}
+	return fileErr
+}
+
+// logFailure logs the first write error only, to avoid flooding the log.
+// Must be called with lock held.
+func (c *Capture) logFailure(err error) {
+	if !c.failed {
+		slog.Warn("capture_write_failed", "error", err)
+		c.failed = true
+	}
+}
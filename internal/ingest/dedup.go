@@ -0,0 +1,224 @@
+package ingest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/polyinsider/engine/internal/store"
+)
+
+const (
+	// DefaultDedupWindow is how long a seen trade is remembered
+	DefaultDedupWindow = 2 * time.Minute
+	// DefaultDedupMaxEntries bounds the number of remembered keys
+	DefaultDedupMaxEntries = 100000
+	// FingerprintBucket is the timestamp granularity of the fallback fingerprint
+	FingerprintBucket = 2 * time.Second
+)
+
+// DuplicateObserver is notified of every trade dropped as a duplicate.
+type DuplicateObserver interface {
+	IncrementDuplicates(source string)
+}
+
+// Deduper drops trades already seen from any source within a time window.
+// Trades are matched on TradeID, then TransactionHash, then a fingerprint
+// of asset, price, size, maker and timestamp bucket. The fingerprint only
+// applies between trades with no identifier in common, so split fills that
+// repeat a size and price are kept apart by their IDs.
+type Deduper struct {
+	next       TradeSink
+	window     time.Duration
+	maxEntries int
+	observer   DuplicateObserver
+	now        func() time.Time
+
+	mu    sync.Mutex
+	seen  map[string]time.Time // key -> time first seen
+	order []seenKey            // keys in insertion order, for eviction
+}
+
+// seenKey is an entry in the eviction queue.
+type seenKey struct {
+	key string
+	at  time.Time
+}
+
+// NewDeduper creates a Deduper that forwards first-seen trades to next.
+func NewDeduper(next TradeSink, window time.Duration, maxEntries int) *Deduper {
+	if window <= 0 {
+		window = DefaultDedupWindow
+	}
+	if maxEntries <= 0 {
+		maxEntries = DefaultDedupMaxEntries
+	}
+	return &Deduper{
+		next:       next,
+		window:     window,
+		maxEntries: maxEntries,
+		now:        time.Now,
+		seen:       make(map[string]time.Time),
+	}
+}
+
+// SetObserver sets the observer notified of dropped duplicates.
+func (d *Deduper) SetObserver(o DuplicateObserver) {
+	d.observer = o
+}
+
+// Publish forwards trade unless it duplicates one seen within the window.
+// Book updates are not fills and always pass through.
+func (d *Deduper) Publish(trade store.Trade) {
+	if strings.HasPrefix(trade.ValuationMethod, ValuationNoSize) {
+		d.next.Publish(trade)
+		return
+	}
+
+	if d.isDuplicate(trade) {
+		if d.observer != nil {
+			d.observer.IncrementDuplicates(sourceName(trade.Source))
+		}
+		return
+	}
+	d.next.Publish(trade)
+}
+
+// Len returns the number of remembered keys.
+func (d *Deduper) Len() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.seen)
+}
+
+// isDuplicate checks trade against the seen keys and records its keys if new.
+func (d *Deduper) isDuplicate(trade store.Trade) bool {
+	keys := dedupKeys(trade)
+	now := d.now()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.evict(now)
+
+	for _, key := range keys.match {
+		if _, ok := d.seen[key]; ok {
+			return true
+		}
+	}
+
+	for _, key := range keys.record {
+		d.seen[key] = now
+		d.order = append(d.order, seenKey{key: key, at: now})
+	}
+	d.evict(now)
+	return false
+}
+
+// evict removes keys older than the window or beyond maxEntries.
+// Must be called with lock held.
+func (d *Deduper) evict(now time.Time) {
+	cutoff := now.Add(-d.window)
+	i := 0
+	for ; i < len(d.order); i++ {
+		e := d.order[i]
+		if e.at.After(cutoff) && len(d.seen) <= d.maxEntries {
+			break
+		}
+		// Only delete if the map entry was not refreshed by a later insert
+		if at, ok := d.seen[e.key]; ok && !at.After(e.at) {
+			delete(d.seen, e.key)
+		}
+	}
+	if i > 0 {
+		d.order = append(d.order[:0], d.order[i:]...)
+	}
+}
+
+// tradeKeys holds the keys a trade is matched on and the keys it is recorded under.
+type tradeKeys struct {
+	match  []string
+	record []string
+}
+
+// Identifier kinds of a trade, used to namespace its fingerprint.
+const (
+	identTradeID = 1 << iota
+	identTxHash
+	identAll = identTradeID | identTxHash
+)
+
+// dedupKeys builds the identity keys of trade. The fingerprint is recorded
+// in its own bucket but matched against the neighbouring bucket as well, so
+// two copies straddling a bucket boundary still collide. It is recorded
+// under the trade's identifier kinds and matched only against kinds the
+// trade lacks: trades sharing an identifier kind are told apart by it.
+func dedupKeys(trade store.Trade) tradeKeys {
+	var k tradeKeys
+
+	idents := 0
+	if trade.TradeID != "" {
+		idents |= identTradeID
+	}
+	if trade.TransactionHash != "" {
+		idents |= identTxHash
+	}
+
+	if trade.TradeID != "" {
+		key := "id:" + trade.TradeID
+		k.match = append(k.match, key)
+		k.record = append(k.record, key)
+	}
+
+	if trade.TransactionHash != "" {
+		// One transaction can settle several fills; qualify by asset, maker and size
+		key := fmt.Sprintf("tx:%s|%s|%s|%s", strings.ToLower(trade.TransactionHash),
+			trade.AssetID, strings.ToLower(trade.MakerAddress), normalizeNumber(trade.Size))
+		k.match = append(k.match, key)
+		k.record = append(k.record, key)
+	}
+
+	bucket := trade.Timestamp.UnixMilli() / FingerprintBucket.Milliseconds()
+	for _, b := range []int64{bucket, bucket - 1, bucket + 1} {
+		for stored := 0; stored <= identAll; stored++ {
+			if stored&idents == 0 {
+				k.match = append(k.match, fingerprint(trade, stored, b))
+			}
+		}
+	}
+	k.record = append(k.record, fingerprint(trade, idents, bucket))
+
+	return k
+}
+
+// fingerprint builds the fallback identity of a fill for a timestamp bucket,
+// namespaced by the identifier kinds of the trade that recorded it.
+func fingerprint(trade store.Trade, idents int, bucket int64) string {
+	return fmt.Sprintf("fp%d:%s|%s|%s|%s|%d",
+		idents,
+		trade.AssetID,
+		strconv.FormatFloat(trade.Price, 'f', -1, 64),
+		normalizeNumber(trade.Size),
+		strings.ToLower(trade.MakerAddress),
+		bucket,
+	)
+}
+
+// normalizeNumber renders a numeric string canonically ("100.0" == "100").
+func normalizeNumber(s string) string {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return s
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// sourceName returns the source label used in metrics.
+func sourceName(source string) string {
+	if source == "" {
+		return "unknown"
+	}
+	return source
+}
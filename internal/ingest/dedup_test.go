@@ -0,0 +1,103 @@
+package ingest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/polyinsider/engine/internal/store"
+)
+
+type duplicateCounter map[string]int
+
+func (c duplicateCounter) IncrementDuplicates(source string) {
+	c[source]++
+}
+
+func TestDeduperDropsCrossSourceDuplicates(t *testing.T) {
+	out := &tradeRecorder{}
+	counts := duplicateCounter{}
+	dedup := NewDeduper(out, time.Minute, 100)
+	dedup.SetObserver(counts)
+
+	ts := time.UnixMilli(1_700_000_001_900)
+	fill := store.Trade{AssetID: "a", MakerAddress: "0xAbC", Size: "100", Price: 0.42, Timestamp: ts}
+
+	byID := fill
+	byID.TradeID, byID.Source = "t-1", store.SourceWebSocket
+	dedup.Publish(byID)
+	byID.Source = store.SourceREST
+	dedup.Publish(byID)
+
+	// Same fill without IDs, across a bucket boundary and with a different size format
+	other := store.Trade{AssetID: "b", MakerAddress: "0xabc", Size: "5", Price: 0.1, Timestamp: ts, Source: store.SourceWebSocket}
+	dedup.Publish(other)
+	other.Size, other.Timestamp, other.Source = "5.0", ts.Add(300*time.Millisecond), store.SourceREST
+	dedup.Publish(other)
+
+	// A different fill in the same transaction is kept
+	txA := store.Trade{AssetID: "c", MakerAddress: "0x1", Size: "10", Price: 0.5, TransactionHash: "0xTX", Timestamp: ts}
+	txB := txA
+	txB.MakerAddress, txB.Size = "0x2", "20"
+	dedup.Publish(txA)
+	dedup.Publish(txB)
+
+	// Book updates are never deduplicated
+	book := store.Trade{AssetID: "a", Price: 0.42, Size: "book_update", ValuationMethod: ValuationNoSize, Timestamp: ts}
+	dedup.Publish(book)
+	dedup.Publish(book)
+
+	if len(out.trades) != 6 {
+		t.Fatalf("Expected 6 trades forwarded, got %d", len(out.trades))
+	}
+	if counts[store.SourceREST] != 2 || counts[store.SourceWebSocket] != 0 {
+		t.Errorf("Unexpected duplicate counts %v", counts)
+	}
+}
+
+func TestDeduperKeepsSplitFills(t *testing.T) {
+	out := &tradeRecorder{}
+	dedup := NewDeduper(out, time.Minute, 100)
+
+	// Split order: same maker, size and price, distinct fills 1.5s apart
+	ts := time.UnixMilli(1_700_000_000_000)
+	first := store.Trade{TradeID: "t-1", AssetID: "a", MakerAddress: "0xabc", Size: "50", Price: 0.3, Timestamp: ts}
+	second := first
+	second.TradeID, second.Timestamp = "t-2", ts.Add(1500*time.Millisecond)
+	dedup.Publish(first)
+	dedup.Publish(second)
+	if len(out.trades) != 2 {
+		t.Fatalf("Expected both split fills forwarded, got %d", len(out.trades))
+	}
+
+	// A copy of the second fill without an ID still matches its fingerprint
+	anonymous := second
+	anonymous.TradeID = ""
+	dedup.Publish(anonymous)
+	if len(out.trades) != 2 {
+		t.Errorf("Expected the ID-less copy dropped, got %d trades", len(out.trades))
+	}
+}
+
+func TestDeduperForgetsAfterWindow(t *testing.T) {
+	out := &tradeRecorder{}
+	dedup := NewDeduper(out, time.Minute, 4)
+	now := time.Unix(1_700_000_000, 0)
+	dedup.now = func() time.Time { return now }
+
+	trade := store.Trade{TradeID: "t-1", AssetID: "a", Size: "1", Price: 0.5, Timestamp: now}
+	dedup.Publish(trade)
+
+	now = now.Add(2 * time.Minute)
+	dedup.Publish(trade)
+	if len(out.trades) != 2 {
+		t.Fatalf("Expected trade forwarded again after the window, got %d", len(out.trades))
+	}
+
+	// Keys beyond the entry cap are evicted oldest first
+	for _, id := range []string{"t-2", "t-3", "t-4"} {
+		dedup.Publish(store.Trade{TradeID: id, AssetID: id, Size: "1", Price: 0.5, Timestamp: now})
+	}
+	if n := dedup.Len(); n > 4 {
+		t.Errorf("Expected at most 4 keys retained, got %d", n)
+	}
+}
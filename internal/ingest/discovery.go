@@ -0,0 +1,180 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/polyinsider/engine/internal/store"
+)
+
+// MarketFetcher returns the current set of active markets.
+type MarketFetcher func() ([]Market, error)
+
+// AssetSubscriber applies a new set of subscribed asset IDs.
+type AssetSubscriber interface {
+	UpdateAssetIDs(ids []string) error
+}
+
+// MarketEventSink receives market added/closed events.
+type MarketEventSink interface {
+	Publish(event store.MarketEvent)
+}
+
+// Discovery periodically re-fetches active markets and keeps the
+// subscribed token set in sync.
+type Discovery struct {
+	fetch      MarketFetcher
+	subscriber AssetSubscriber
+	sink       MarketEventSink
+	registry   *AssetRegistry
+	interval   time.Duration
+	markets    map[string]Market // market ID -> market
+	tokenCount int
+}
+
+// NewDiscovery creates a market discovery loop.
+func NewDiscovery(fetch MarketFetcher, subscriber AssetSubscriber, sink MarketEventSink, interval time.Duration) *Discovery {
+	return &Discovery{
+		fetch:      fetch,
+		subscriber: subscriber,
+		sink:       sink,
+		interval:   interval,
+		markets:    make(map[string]Market),
+	}
+}
+
+// SetRegistry sets the asset registry kept in sync on every refresh.
+func (d *Discovery) SetRegistry(r *AssetRegistry) {
+	d.registry = r
+}
+
+// Run refreshes markets every interval until ctx is cancelled.
+func (d *Discovery) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := d.Refresh(); err != nil {
+				slog.Warn("market_refresh_failed", "error", err)
+			}
+		}
+	}
+}
+
+// Refresh fetches active markets, emits events for markets that appeared or
+// disappeared, and pushes the new token set to the subscriber.
+// On error the current subscriptions are left untouched.
+func (d *Discovery) Refresh() error {
+	fetched, err := d.fetch()
+	if err != nil {
+		return err
+	}
+
+	current := make(map[string]Market, len(fetched))
+	active := make([]Market, 0, len(fetched))
+	for _, market := range fetched {
+		if market.Closed || market.ID == "" {
+			continue
+		}
+		if _, dup := current[market.ID]; dup {
+			continue
+		}
+		current[market.ID] = market
+		active = append(active, market)
+	}
+
+	// An empty response is far more likely an API hiccup than every market resolving
+	if len(current) == 0 && len(d.markets) > 0 {
+		return fmt.Errorf("no active markets returned, keeping %d subscribed", len(d.markets))
+	}
+
+	// Register new markets before subscribing so their first trades are
+	// enriched; closed markets stay registered until they are unsubscribed
+	if d.registry != nil {
+		d.registry.Merge(active)
+	}
+
+	tokenIDs := ExtractTokenIDs(active)
+	if err := d.subscriber.UpdateAssetIDs(tokenIDs); err != nil {
+		return fmt.Errorf("failed to update subscriptions: %w", err)
+	}
+	if d.registry != nil {
+		d.registry.Replace(active)
+	}
+
+	now := time.Now()
+	added, closed := 0, 0
+	for _, market := range active {
+		if _, known := d.markets[market.ID]; !known {
+			d.sink.Publish(newMarketEvent(store.MarketAdded, market, now))
+			added++
+		}
+	}
+	for id, market := range d.markets {
+		if _, still := current[id]; !still {
+			d.sink.Publish(newMarketEvent(store.MarketClosed, market, now))
+			closed++
+		}
+	}
+
+	d.markets = current
+	d.tokenCount = len(tokenIDs)
+
+	slog.Info("markets_refreshed",
+		"active", len(current),
+		"added", added,
+		"closed", closed,
+		"token_count", len(tokenIDs),
+	)
+	return nil
+}
+
+// TokenCount returns the number of tokens subscribed after the last refresh.
+func (d *Discovery) TokenCount() int {
+	return d.tokenCount
+}
+
+// newMarketEvent builds a MarketEvent for market.
+func newMarketEvent(eventType string, market Market, ts time.Time) store.MarketEvent {
+	var tokenIDs []string
+	if market.ClobTokenIDs != "" {
+		_ = json.Unmarshal([]byte(market.ClobTokenIDs), &tokenIDs)
+	}
+
+	return store.MarketEvent{
+		Type:      eventType,
+		MarketID:  coalesce(market.ConditionID, market.ID),
+		Question:  market.Question,
+		Slug:      market.Slug,
+		TokenIDs:  tokenIDs,
+		Timestamp: ts,
+	}
+}
+
+// diffIDs returns the IDs present in next but not prev, and in prev but not next.
+func diffIDs(prev, next []string) (added, removed []string) {
+	prevSet := make(map[string]bool, len(prev))
+	for _, id := range prev {
+		prevSet[id] = true
+	}
+	nextSet := make(map[string]bool, len(next))
+	for _, id := range next {
+		nextSet[id] = true
+		if !prevSet[id] {
+			added = append(added, id)
+		}
+	}
+	for _, id := range prev {
+		if !nextSet[id] {
+			removed = append(removed, id)
+		}
+	}
+	return added, removed
+}
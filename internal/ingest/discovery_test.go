@@ -0,0 +1,250 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/polyinsider/engine/internal/store"
+)
+
+type fakeSubscriber struct {
+	ids []string
+}
+
+func (f *fakeSubscriber) UpdateAssetIDs(ids []string) error {
+	f.ids = ids
+	return nil
+}
+
+type eventRecorder struct {
+	events []store.MarketEvent
+}
+
+func (r *eventRecorder) Publish(event store.MarketEvent) {
+	r.events = append(r.events, event)
+}
+
+func TestDiscoveryDiffsMarkets(t *testing.T) {
+	rounds := [][]Market{
+		{
+			{ID: "1", Question: "A?", ClobTokenIDs: `["a1","a2"]`},
+			{ID: "2", Question: "B?", ClobTokenIDs: `["b1","b2"]`},
+		},
+		{
+			{ID: "2", Question: "B?", ClobTokenIDs: `["b1","b2"]`},
+			{ID: "3", Question: "C?", ClobTokenIDs: `["c1","c2"]`},
+		},
+		{}, // API hiccup: must not unsubscribe everything
+	}
+	round := 0
+	fetch := func() ([]Market, error) {
+		markets := rounds[round]
+		round++
+		return markets, nil
+	}
+
+	sub := &fakeSubscriber{}
+	events := &eventRecorder{}
+	d := NewDiscovery(fetch, sub, events, time.Minute)
+
+	if err := d.Refresh(); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if len(events.events) != 2 || d.TokenCount() != 4 {
+		t.Fatalf("Expected 2 added events and 4 tokens, got %d events and %d tokens", len(events.events), d.TokenCount())
+	}
+
+	events.events = nil
+	if err := d.Refresh(); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	got := map[string]string{}
+	for _, e := range events.events {
+		got[e.MarketID] = e.Type
+	}
+	if len(got) != 2 || got["3"] != store.MarketAdded || got["1"] != store.MarketClosed {
+		t.Errorf("Expected market 3 added and 1 closed, got %v", got)
+	}
+	if strings.Join(sub.ids, ",") != "b1,b2,c1,c2" {
+		t.Errorf("Unexpected subscribed tokens %v", sub.ids)
+	}
+
+	if err := d.Refresh(); err == nil {
+		t.Error("Expected error for empty market list")
+	}
+	if len(sub.ids) != 4 {
+		t.Errorf("Expected subscriptions kept after empty refresh, got %v", sub.ids)
+	}
+}
+
+// registrySubscriber records which tokens the registry knows when the
+// subscription is updated.
+type registrySubscriber struct {
+	registry *AssetRegistry
+	known    map[string]bool
+	err      error
+}
+
+func (s *registrySubscriber) UpdateAssetIDs(ids []string) error {
+	s.known = map[string]bool{}
+	for _, id := range []string{"a1", "b1"} {
+		_, s.known[id] = s.registry.Lookup(id)
+	}
+	return s.err
+}
+
+func TestDiscoveryRegistersBeforeSubscribing(t *testing.T) {
+	rounds := [][]Market{
+		{{ID: "1", Question: "A?", ClobTokenIDs: `["a1","a2"]`}},
+		{{ID: "2", Question: "B?", ClobTokenIDs: `["b1","b2"]`}},
+	}
+	round := 0
+	fetch := func() ([]Market, error) {
+		markets := rounds[round%len(rounds)]
+		round++
+		return markets, nil
+	}
+
+	registry := NewAssetRegistry()
+	sub := &registrySubscriber{registry: registry}
+	d := NewDiscovery(fetch, sub, &eventRecorder{}, time.Minute)
+	d.SetRegistry(registry)
+	if err := d.Refresh(); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+
+	// A failed update keeps the closed market and already knows the new one
+	sub.err = fmt.Errorf("write failed")
+	if err := d.Refresh(); err == nil {
+		t.Fatal("Expected the subscription error")
+	}
+	if !sub.known["a1"] || !sub.known["b1"] {
+		t.Errorf("Expected both markets registered while subscribing, got %v", sub.known)
+	}
+	if _, ok := registry.Lookup("a1"); !ok {
+		t.Error("Expected the closed market kept after a failed update")
+	}
+
+	// Once the update succeeds the closed market is dropped
+	sub.err = nil
+	round = 1
+	if err := d.Refresh(); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if _, ok := registry.Lookup("a1"); ok {
+		t.Error("Expected the closed market removed after subscribing")
+	}
+	if _, ok := registry.Lookup("b1"); !ok {
+		t.Error("Expected the new market registered")
+	}
+}
+
+func TestListenerUpdatesLiveSubscription(t *testing.T) {
+	received := make(chan map[string]interface{}, 10)
+	upgrader := websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			var msg map[string]interface{}
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+			received <- msg
+		}
+	}))
+	defer srv.Close()
+
+	l := NewListener("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	l.SetAssetIDs([]string{"a", "b"})
+	if err := l.connect(context.Background()); err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	defer l.closeConnection()
+
+	if msg := <-received; msg["type"] != "market" {
+		t.Fatalf("Expected initial market subscription, got %v", msg)
+	}
+
+	if err := l.UpdateAssetIDs([]string{"b", "c"}); err != nil {
+		t.Fatalf("UpdateAssetIDs failed: %v", err)
+	}
+
+	for _, want := range []struct{ op, id string }{{"subscribe", "c"}, {"unsubscribe", "a"}} {
+		select {
+		case msg := <-received:
+			ids, _ := msg["assets_ids"].([]interface{})
+			if msg["operation"] != want.op || len(ids) != 1 || ids[0] != want.id {
+				t.Errorf("Expected %s [%s], got %v", want.op, want.id, msg)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Timed out waiting for %s", want.op)
+		}
+	}
+}
+
+func TestListenerSerialisesWrites(t *testing.T) {
+	upgrader := websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer srv.Close()
+
+	l := NewListener("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	l.SetAssetIDs([]string{"a"})
+	if err := l.connect(context.Background()); err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	defer l.closeConnection()
+
+	// Stale enough that every heartbeat check pings
+	l.lastMsgMu.Lock()
+	l.lastMsg = time.Now().Add(-2 * HeartbeatTimeout)
+	l.lastMsgMu.Unlock()
+
+	// Subscription updates from discovery race heartbeat pings
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			ids := []string{"a", fmt.Sprintf("x%d", i)}
+			if err := l.UpdateAssetIDs(ids); err != nil {
+				t.Errorf("UpdateAssetIDs failed: %v", err)
+				return
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			l.checkHeartbeat()
+		}
+	}()
+	wg.Wait()
+
+	l.connMu.Lock()
+	defer l.connMu.Unlock()
+	if l.conn == nil {
+		t.Error("Expected the connection to survive concurrent writes")
+	}
+}
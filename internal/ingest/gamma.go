@@ -0,0 +1,228 @@
+package ingest
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultGammaPageSize is the number of markets requested per page
+	DefaultGammaPageSize = 100
+	// MaxGammaPages bounds pagination per query in case the API never returns
+	// a short page; the markets fetched up to the limit are kept
+	MaxGammaPages = 500
+)
+
+// MarketTag is a tag attached to a Gamma market (requested with include_tag=true).
+type MarketTag struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+	Slug  string `json:"slug"`
+}
+
+// MarketFilter selects which active markets to subscribe to.
+// Zero values disable the corresponding filter; all set filters must match.
+type MarketFilter struct {
+	Tags          []string      // match any tag slug or ID, also sent to the API (case-insensitive)
+	MinVolume     float64       // minimum lifetime volume in USD
+	MinLiquidity  float64       // minimum current liquidity in USD
+	MinTimeToEnd  time.Duration // market must end at least this far in the future
+	MaxTimeToEnd  time.Duration // market must end within this window
+	SlugAllowlist []string      // if set, only these slugs are kept
+	SlugDenylist  []string      // these slugs are always dropped
+}
+
+// GammaClient pages through the Polymarket Gamma API.
+type GammaClient struct {
+	baseURL    string
+	pageSize   int
+	filter     MarketFilter
+	httpClient *http.Client
+}
+
+// NewGammaClient creates a Gamma client. baseURL is the API root, e.g.
+// https://gamma-api.polymarket.com.
+func NewGammaClient(baseURL string, pageSize int, filter MarketFilter) *GammaClient {
+	if pageSize <= 0 {
+		pageSize = DefaultGammaPageSize
+	}
+	return &GammaClient{
+		baseURL:    strings.TrimSuffix(strings.TrimSuffix(baseURL, "/"), "/markets"),
+		pageSize:   pageSize,
+		filter:     filter,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// FetchActiveMarkets pages through every active market and returns those
+// matching the client's filter. The API takes one tag per query, so each
+// configured tag is paged separately and markets in several are kept once.
+func (g *GammaClient) FetchActiveMarkets() ([]Market, error) {
+	now := time.Now()
+	var selected []Market
+	seen := make(map[string]bool)
+	pages, total := 0, 0
+
+	tags := g.filter.Tags
+	if len(tags) == 0 {
+		tags = []string{""}
+	}
+	for _, tag := range tags {
+		for page := 0; ; page++ {
+			if page == MaxGammaPages {
+				slog.Warn("gamma_page_limit_reached", "tag", tag, "pages", MaxGammaPages)
+				break
+			}
+
+			markets, err := g.fetchPage(page*g.pageSize, tag, now)
+			if err != nil {
+				return nil, err
+			}
+			pages++
+			total += len(markets)
+
+			for _, market := range markets {
+				if !seen[market.ID] && g.filter.Match(market, now) {
+					seen[market.ID] = true
+					selected = append(selected, market)
+				}
+			}
+
+			if len(markets) < g.pageSize {
+				break
+			}
+		}
+	}
+
+	slog.Info("gamma_markets_fetched",
+		"pages", pages,
+		"total", total,
+		"selected", len(selected),
+	)
+	return selected, nil
+}
+
+// fetchPage fetches one page of active markets starting at offset, limited
+// to tag unless it is empty.
+func (g *GammaClient) fetchPage(offset int, tag string, now time.Time) ([]Market, error) {
+	resp, err := g.httpClient.Get(g.pageURL(offset, tag, now))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch markets: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var markets []Market
+	if err := json.NewDecoder(resp.Body).Decode(&markets); err != nil {
+		return nil, fmt.Errorf("failed to decode markets: %w", err)
+	}
+	return markets, nil
+}
+
+// pageURL builds the request URL. The tag and numeric filters are also sent
+// to the API so fewer pages come back; Match re-checks them locally.
+func (g *GammaClient) pageURL(offset int, tag string, now time.Time) string {
+	q := url.Values{}
+	q.Set("active", "true")
+	q.Set("closed", "false")
+	q.Set("include_tag", "true")
+	q.Set("limit", strconv.Itoa(g.pageSize))
+	q.Set("offset", strconv.Itoa(offset))
+
+	if _, err := strconv.Atoi(tag); err == nil {
+		q.Set("tag_id", tag)
+	} else if tag != "" {
+		q.Set("tag_slug", tag)
+	}
+
+	f := g.filter
+	if f.MinVolume > 0 {
+		q.Set("volume_num_min", strconv.FormatFloat(f.MinVolume, 'f', -1, 64))
+	}
+	if f.MinLiquidity > 0 {
+		q.Set("liquidity_num_min", strconv.FormatFloat(f.MinLiquidity, 'f', -1, 64))
+	}
+	if f.MinTimeToEnd > 0 {
+		q.Set("end_date_min", now.Add(f.MinTimeToEnd).UTC().Format(time.RFC3339))
+	}
+	if f.MaxTimeToEnd > 0 {
+		q.Set("end_date_max", now.Add(f.MaxTimeToEnd).UTC().Format(time.RFC3339))
+	}
+
+	return g.baseURL + "/markets?" + q.Encode()
+}
+
+// Match reports whether market passes every configured filter.
+func (f MarketFilter) Match(market Market, now time.Time) bool {
+	if market.Closed {
+		return false
+	}
+
+	if containsFold(f.SlugDenylist, market.Slug) {
+		return false
+	}
+	if len(f.SlugAllowlist) > 0 && !containsFold(f.SlugAllowlist, market.Slug) {
+		return false
+	}
+
+	if len(f.Tags) > 0 && !f.matchTags(market) {
+		return false
+	}
+
+	if f.MinVolume > 0 && market.VolumeNum < f.MinVolume {
+		return false
+	}
+	if f.MinLiquidity > 0 && market.LiquidityNum < f.MinLiquidity {
+		return false
+	}
+
+	if f.MinTimeToEnd > 0 || f.MaxTimeToEnd > 0 {
+		end, err := time.Parse(time.RFC3339, market.EndDate)
+		if err != nil {
+			return false
+		}
+		if f.MinTimeToEnd > 0 && end.Before(now.Add(f.MinTimeToEnd)) {
+			return false
+		}
+		if f.MaxTimeToEnd > 0 && end.After(now.Add(f.MaxTimeToEnd)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchTags reports whether any filter tag matches the market category or tags.
+func (f MarketFilter) matchTags(market Market) bool {
+	if containsFold(f.Tags, market.Category) {
+		return true
+	}
+	for _, tag := range market.Tags {
+		if containsFold(f.Tags, tag.Slug) || containsFold(f.Tags, tag.ID) || containsFold(f.Tags, tag.Label) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsFold reports whether list contains s, ignoring case.
+func containsFold(list []string, s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package ingest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestGammaPaginatesAllMarkets(t *testing.T) {
+	const total = 7
+	var offsets []int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		offsets = append(offsets, offset)
+
+		page := []Market{}
+		for i := offset; i < total && i < offset+limit; i++ {
+			page = append(page, Market{ID: strconv.Itoa(i), Slug: fmt.Sprintf("m-%d", i)})
+		}
+		json.NewEncoder(w).Encode(page)
+	}))
+	defer srv.Close()
+
+	markets, err := NewGammaClient(srv.URL, 3, MarketFilter{}).FetchActiveMarkets()
+	if err != nil {
+		t.Fatalf("FetchActiveMarkets failed: %v", err)
+	}
+	if len(markets) != total {
+		t.Errorf("Expected %d markets, got %d", total, len(markets))
+	}
+	if fmt.Sprint(offsets) != "[0 3 6]" {
+		t.Errorf("Expected offsets [0 3 6], got %v", offsets)
+	}
+}
+
+func TestGammaKeepsMarketsAtPageLimit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Never a short page
+		offset := r.URL.Query().Get("offset")
+		json.NewEncoder(w).Encode([]Market{{ID: offset, Slug: "m-" + offset}})
+	}))
+	defer srv.Close()
+
+	markets, err := NewGammaClient(srv.URL, 1, MarketFilter{}).FetchActiveMarkets()
+	if err != nil {
+		t.Fatalf("FetchActiveMarkets failed: %v", err)
+	}
+	if len(markets) != MaxGammaPages {
+		t.Errorf("Expected the %d markets fetched before the limit, got %d", MaxGammaPages, len(markets))
+	}
+}
+
+func TestGammaQueriesEachTag(t *testing.T) {
+	var queries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		queries = append(queries, "slug="+q.Get("tag_slug")+" id="+q.Get("tag_id"))
+
+		shared := Market{ID: "1", Tags: []MarketTag{{ID: "100", Slug: "politics"}}}
+		switch {
+		case q.Get("tag_slug") == "politics":
+			json.NewEncoder(w).Encode([]Market{shared})
+		case q.Get("tag_id") == "100":
+			json.NewEncoder(w).Encode([]Market{shared, {ID: "2", Tags: []MarketTag{{ID: "100"}}}})
+		default:
+			json.NewEncoder(w).Encode([]Market{})
+		}
+	}))
+	defer srv.Close()
+
+	markets, err := NewGammaClient(srv.URL, 10, MarketFilter{Tags: []string{"politics", "100"}}).FetchActiveMarkets()
+	if err != nil {
+		t.Fatalf("FetchActiveMarkets failed: %v", err)
+	}
+	if fmt.Sprint(queries) != "[slug=politics id= slug= id=100]" {
+		t.Errorf("Expected one query per tag, got %v", queries)
+	}
+	if len(markets) != 2 {
+		t.Errorf("Expected markets in both tags kept once, got %+v", markets)
+	}
+}
+
+func TestMarketFilter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	base := Market{
+		Slug:         "fed-cuts-in-march",
+		Tags:         []MarketTag{{Label: "Economy", Slug: "economy"}},
+		VolumeNum:    50000,
+		LiquidityNum: 10000,
+		EndDate:      "2026-03-01T00:00:00Z",
+	}
+	filter := MarketFilter{
+		Tags:         []string{"politics", "economy"},
+		MinVolume:    10000,
+		MinLiquidity: 5000,
+		MaxTimeToEnd: 90 * 24 * time.Hour,
+		SlugDenylist: []string{"spam-market"},
+	}
+
+	if !filter.Match(base, now) {
+		t.Error("Expected base market to match")
+	}
+
+	cases := map[string]func(m *Market){
+		"wrong tag":     func(m *Market) { m.Tags = []MarketTag{{Slug: "sports"}} },
+		"low volume":    func(m *Market) { m.VolumeNum = 500 },
+		"low liquidity": func(m *Market) { m.LiquidityNum = 100 },
+		"ends too late": func(m *Market) { m.EndDate = "2027-01-01T00:00:00Z" },
+		"denylisted":    func(m *Market) { m.Slug = "spam-market" },
+		"closed":        func(m *Market) { m.Closed = true },
+		"missing end":   func(m *Market) { m.EndDate = "" },
+	}
+	for name, mutate := range cases {
+		m := base
+		mutate(&m)
+		if filter.Match(m, now) {
+			t.Errorf("%s: expected market to be filtered out", name)
+		}
+	}
+
+	allow := MarketFilter{SlugAllowlist: []string{"other-market"}}
+	if allow.Match(base, now) {
+		t.Error("Expected allowlist to exclude unlisted slug")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/polyinsider/engine/internal/orderbook"
 	"github.com/polyinsider/engine/internal/store"
 )
 
@@ -20,20 +21,26 @@ type WSMessage struct {
 // BookEvent represents an orderbook snapshot from the market channel.
 // This is the actual format received from Polymarket WebSocket.
 type BookEvent struct {
-	Market         string `json:"market"`          // Condition ID
-	AssetID        string `json:"asset_id"`        // Token ID
-	Timestamp      string `json:"timestamp"`       // Unix timestamp in ms
-	Hash           string `json:"hash"`            // Event hash
-	EventType      string `json:"event_type"`      // "book", "price_change", etc.
-	LastTradePrice string `json:"last_trade_price"` // Last executed trade price
-	Bids           []struct {
-		Price string `json:"price"`
-		Size  string `json:"size"`
-	} `json:"bids"`
-	Asks []struct {
-		Price string `json:"price"`
-		Size  string `json:"size"`
-	} `json:"asks"`
+	Market         string               `json:"market"`           // Condition ID
+	AssetID        string               `json:"asset_id"`         // Token ID
+	Timestamp      string               `json:"timestamp"`        // Unix timestamp in ms
+	Hash           string               `json:"hash"`             // Event hash
+	EventType      string               `json:"event_type"`       // "book", "price_change", etc.
+	LastTradePrice string               `json:"last_trade_price"` // Last executed trade price
+	Bids           []orderbook.RawLevel `json:"bids"`
+	Asks           []orderbook.RawLevel `json:"asks"`
+	Changes        []BookChange         `json:"changes"`       // price_change, per-asset format
+	PriceChanges   []BookChange         `json:"price_changes"` // price_change, multi-asset format
+}
+
+// BookChange is one level update in a price_change event.
+type BookChange struct {
+	AssetID string `json:"asset_id"` // only in the multi-asset format
+	Price   string `json:"price"`
+	Side    string `json:"side"`
+	Size    string `json:"size"`  // new aggregate size at the level
+	Delta   string `json:"delta"` // size change, if sent instead of size
+	Hash    string `json:"hash"`  // book hash after the change, only in the multi-asset format
 }
 
 // TradeData represents trade data from the Polymarket WebSocket.
@@ -67,24 +74,45 @@ type TradeEvent struct {
 // LastTradePriceEvent represents the last_trade_price WebSocket event.
 // This is the primary event for trade execution data from Polymarket.
 type LastTradePriceEvent struct {
-	Type    string `json:"type"`     // "last_trade_price"
-	AssetID string `json:"asset_id"` // Token ID
-	Price   string `json:"price"`    // Execution price
-	Size    string `json:"size"`     // Trade size
-	Side    string `json:"side"`     // BUY or SELL (if available)
-	Maker   string `json:"maker"`    // Maker address (if available)
-	Taker   string `json:"taker"`    // Taker address (if available)
+	Type      string `json:"type"`      // "last_trade_price"
+	AssetID   string `json:"asset_id"`  // Token ID
+	Price     string `json:"price"`     // Execution price
+	Size      string `json:"size"`      // Trade size
+	Side      string `json:"side"`      // BUY or SELL (if available)
+	Maker     string `json:"maker"`     // Maker address (if available)
+	Taker     string `json:"taker"`     // Taker address (if available)
+	Timestamp string `json:"timestamp"` // Event time in ms (if available)
 }
 
 // ParseMessage parses a raw WebSocket message and returns trades if present.
+// Trades without an event timestamp are stamped with the current time.
 func ParseMessage(data []byte) ([]store.Trade, string, error) {
+	trades, _, msgType, err := parseMessage(data, time.Now())
+	return trades, msgType, err
+}
+
+// parseMessage parses a raw WebSocket message received at the given time and
+// returns any trades together with the decoded book events. Trades without
+// an event timestamp fall back to the receive time.
+func parseMessage(data []byte, received time.Time) ([]store.Trade, []BookEvent, string, error) {
+	trades, bookEvents, msgType, err := decodeMessage(data, received)
+	for i := range trades {
+		if trades[i].Timestamp.IsZero() {
+			trades[i].Timestamp = received
+		}
+	}
+	return trades, bookEvents, msgType, err
+}
+
+// decodeMessage detects the message format and decodes it.
+func decodeMessage(data []byte, received time.Time) ([]store.Trade, []BookEvent, string, error) {
 	// First, try to parse as an array of BookEvents (the actual format from Polymarket)
 	var bookEvents []BookEvent
 	if err := json.Unmarshal(data, &bookEvents); err == nil && len(bookEvents) > 0 {
 		// Check if these are book events
 		if bookEvents[0].EventType == "book" || bookEvents[0].EventType == "price_change" {
 			trades := parseBookEvents(bookEvents)
-			return trades, "book_array", nil
+			return trades, bookEvents, "book_array", nil
 		}
 	}
 
@@ -92,35 +120,76 @@ func ParseMessage(data []byte) ([]store.Trade, string, error) {
 	var singleBook BookEvent
 	if err := json.Unmarshal(data, &singleBook); err == nil && singleBook.EventType != "" {
 		trades := parseBookEvents([]BookEvent{singleBook})
-		return trades, singleBook.EventType, nil
+		return trades, []BookEvent{singleBook}, singleBook.EventType, nil
 	}
 
 	// Try to parse as WSMessage wrapper
 	var msg WSMessage
 	if err := json.Unmarshal(data, &msg); err != nil {
-		return nil, "", fmt.Errorf("failed to unmarshal message: %w", err)
+		return nil, nil, "", fmt.Errorf("failed to unmarshal message: %w", err)
 	}
 
 	// Handle last_trade_price events
 	if msg.Type == "last_trade_price" {
-		trades, err := parseLastTradePrice(data)
+		trades, err := parseLastTradePrice(data, received)
 		if err != nil {
-			return nil, msg.Type, err
+			return nil, nil, msg.Type, err
 		}
-		return trades, msg.Type, nil
+		return trades, nil, msg.Type, nil
 	}
 
 	// Handle trade events
 	if msg.Type == "trade" {
 		trades, err := parseTrades(msg.Data)
 		if err != nil {
-			return nil, msg.Type, err
+			return nil, nil, msg.Type, err
 		}
-		return trades, msg.Type, nil
+		return trades, nil, msg.Type, nil
 	}
 
 	// Return message type for other messages
-	return nil, msg.Type, nil
+	return nil, nil, msg.Type, nil
+}
+
+// bookUpdates converts book events into order book snapshots and level changes.
+func bookUpdates(events []BookEvent) ([]orderbook.Snapshot, []orderbook.PriceChange) {
+	var snapshots []orderbook.Snapshot
+	var changes []orderbook.PriceChange
+
+	for _, event := range events {
+		switch event.EventType {
+		case "book":
+			snapshots = append(snapshots, orderbook.Snapshot{
+				Market:    event.Market,
+				AssetID:   event.AssetID,
+				Timestamp: event.Timestamp,
+				Hash:      event.Hash,
+				Bids:      event.Bids,
+				Asks:      event.Asks,
+			})
+		case "price_change":
+			levels := append(event.Changes, event.PriceChanges...)
+			for i, c := range levels {
+				// A per-asset event's hash is of the book after its last change
+				hash := c.Hash
+				if hash == "" && i == len(levels)-1 {
+					hash = event.Hash
+				}
+				changes = append(changes, orderbook.PriceChange{
+					Market:    event.Market,
+					AssetID:   coalesce(c.AssetID, event.AssetID),
+					Timestamp: event.Timestamp,
+					Side:      c.Side,
+					Price:     c.Price,
+					Size:      c.Size,
+					Delta:     c.Delta,
+					Hash:      hash,
+				})
+			}
+		}
+	}
+
+	return snapshots, changes
 }
 
 // parseBookEvents extracts trade information from book events.
@@ -153,6 +222,7 @@ func parseBookEvents(events []BookEvent) []store.Trade {
 		// Mark as 0 so we know it's not a real trade value
 		trade.ValueUSD = 0
 		trade.Size = "book_update"
+		trade.ValuationMethod = ValuationNoSize
 
 		trades = append(trades, trade)
 	}
@@ -160,8 +230,9 @@ func parseBookEvents(events []BookEvent) []store.Trade {
 	return trades
 }
 
-// parseLastTradePrice parses a last_trade_price event.
-func parseLastTradePrice(data []byte) ([]store.Trade, error) {
+// parseLastTradePrice parses a last_trade_price event. Its timestamp is
+// optional; without one the trade is stamped with the receive time.
+func parseLastTradePrice(data []byte, received time.Time) ([]store.Trade, error) {
 	var event LastTradePriceEvent
 	if err := json.Unmarshal(data, &event); err != nil {
 		return nil, fmt.Errorf("failed to parse last_trade_price: %w", err)
@@ -171,18 +242,24 @@ func parseLastTradePrice(data []byte) ([]store.Trade, error) {
 		return nil, nil
 	}
 
+	ts := parseTimestamp(event.Timestamp)
+	if ts.IsZero() {
+		ts = received
+	}
+
 	trade := store.Trade{
-		ID:           fmt.Sprintf("ltp-%s-%d", event.AssetID[:min(8, len(event.AssetID))], time.Now().UnixNano()),
+		ID:           fmt.Sprintf("ltp-%s-%d", event.AssetID[:min(8, len(event.AssetID))], ts.UnixNano()),
 		AssetID:      event.AssetID,
 		MakerAddress: event.Maker,
 		TakerAddress: event.Taker,
 		Side:         event.Side,
 		Size:         event.Size,
 		Price:        parseFloat(event.Price),
-		Timestamp:    time.Now(),
+		Timestamp:    ts,
 	}
 
-	trade.ValueUSD = calculateValueUSD(trade.Size, trade.Price)
+	// last_trade_price reports size in shares
+	ValueTrade(&trade, Shares)
 
 	return []store.Trade{trade}, nil
 }
@@ -242,8 +319,8 @@ func convertTrades(data []TradeData) []store.Trade {
 			Timestamp:       parseTimestamp(td.Timestamp, td.MatchTime),
 		}
 
-		// Calculate USD value (size * price for USDC markets)
-		trade.ValueUSD = calculateValueUSD(trade.Size, trade.Price)
+		// Trade messages report size in shares
+		ValueTrade(&trade, Shares)
 
 		trades = append(trades, trade)
 	}
@@ -282,7 +359,8 @@ func parseFloat(s string) float64 {
 	return f
 }
 
-// parseTimestamp tries multiple timestamp formats.
+// parseTimestamp tries multiple timestamp formats and returns the zero
+// time if none parses.
 func parseTimestamp(values ...string) time.Time {
 	formats := []string{
 		time.RFC3339,
@@ -314,25 +392,5 @@ func parseTimestamp(values ...string) time.Time {
 		}
 	}
 
-	return time.Now()
+	return time.Time{}
 }
-
-// calculateValueUSD computes the USD value of a trade.
-// For Polymarket, size is typically in USDC (6 decimals).
-func calculateValueUSD(sizeStr string, price float64) float64 {
-	size := parseFloat(sizeStr)
-	if size == 0 {
-		return 0
-	}
-
-	// If size looks like raw USDC (large number), divide by 1e6
-	if size > 1e6 {
-		size = size / 1e6
-	}
-
-	// Value = size * price for buy, size * (1-price) for sell
-	// Simplified: just use size as the USD value for now
-	// The actual calculation depends on the trade type
-	return size
-}
-
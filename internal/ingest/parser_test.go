@@ -0,0 +1,33 @@
+package ingest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBookUpdatesFromPriceChangeFormats(t *testing.T) {
+	msg := []byte(`[
+		{"event_type":"book","market":"0xm","asset_id":"a","timestamp":"1","bids":[{"price":"0.5","size":"10"}],"asks":[]},
+		{"event_type":"price_change","market":"0xm","asset_id":"a","timestamp":"2","hash":"h2","changes":[{"price":"0.5","side":"BUY","size":"5"}]},
+		{"event_type":"price_change","market":"0xm","timestamp":"3","price_changes":[{"asset_id":"b","price":"0.4","side":"SELL","size":"7","hash":"h3"}]}
+	]`)
+
+	_, events, _, err := parseMessage(msg, time.Now())
+	if err != nil {
+		t.Fatalf("parseMessage failed: %v", err)
+	}
+
+	snapshots, changes := bookUpdates(events)
+	if len(snapshots) != 1 || len(snapshots[0].Bids) != 1 {
+		t.Fatalf("Expected 1 snapshot with 1 bid, got %+v", snapshots)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("Expected 2 changes, got %d", len(changes))
+	}
+	if changes[0].AssetID != "a" || changes[1].AssetID != "b" || changes[1].Side != "SELL" {
+		t.Errorf("Unexpected changes %+v", changes)
+	}
+	if changes[0].Hash != "h2" || changes[1].Hash != "h3" {
+		t.Errorf("Expected event and per-change hashes, got %q and %q", changes[0].Hash, changes[1].Hash)
+	}
+}
@@ -0,0 +1,230 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// DefaultMaxAssetsPerConn is the default subscription size of one shard.
+const DefaultMaxAssetsPerConn = 500
+
+// ShardObserver receives per-shard connection health from a ListenerPool.
+type ShardObserver interface {
+	SetShardStatus(shard string, status string)
+	IncrementShardReconnects(shard string)
+	SetShardAssets(shard string, assets int)
+	RemoveShard(shard string)
+}
+
+// ListenerPool splits a subscription set across several WebSocket
+// connections. Each shard is an independent Listener with its own
+// reconnect/backoff loop; all shards publish to the same sink.
+type ListenerPool struct {
+	url        string
+	sink       TradeSink
+	books      BookSink
+	capture    FrameRecorder
+	maxPerConn int
+	observer   ShardObserver
+
+	mu         sync.Mutex
+	ctx        context.Context // set by Start; nil until started
+	shards     []*shard
+	assignment map[string]*shard // asset ID -> owning shard
+	nextID     int
+}
+
+// shard is one connection in the pool.
+type shard struct {
+	name     string
+	listener *Listener
+	assets   []string
+}
+
+// NewListenerPool creates a pool that puts at most maxPerConn assets on each connection.
+func NewListenerPool(url string, sink TradeSink, maxPerConn int) *ListenerPool {
+	if maxPerConn <= 0 {
+		maxPerConn = DefaultMaxAssetsPerConn
+	}
+	return &ListenerPool{
+		url:        url,
+		sink:       sink,
+		maxPerConn: maxPerConn,
+		assignment: make(map[string]*shard),
+	}
+}
+
+// SetObserver sets the observer notified of per-shard health. Call before Start.
+func (p *ListenerPool) SetObserver(o ShardObserver) {
+	p.observer = o
+}
+
+// SetBookSink sets the sink that receives order book updates from every shard.
+// Call before Start.
+func (p *ListenerPool) SetBookSink(books BookSink) {
+	p.books = books
+}
+
+// SetCapture sets the recorder that receives raw frames from every shard.
+// Call before the first UpdateAssetIDs.
+func (p *ListenerPool) SetCapture(capture FrameRecorder) {
+	p.capture = capture
+}
+
+// Start connects every shard. Shards created later start immediately.
+func (p *ListenerPool) Start(ctx context.Context) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.ctx = ctx
+	for _, s := range p.shards {
+		s.listener.Start(ctx)
+	}
+}
+
+// Stop shuts down every shard.
+func (p *ListenerPool) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, s := range p.shards {
+		s.listener.Stop()
+	}
+}
+
+// ShardCount returns the number of open shards.
+func (p *ListenerPool) ShardCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.shards)
+}
+
+// UpdateAssetIDs rebalances the subscription set. Existing assets stay on
+// their shard; new assets fill shards with spare capacity before a new
+// connection is opened, and shards left empty are closed.
+func (p *ListenerPool) UpdateAssetIDs(ids []string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	wanted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	touched := make(map[*shard]bool)
+
+	// Remove assets no longer wanted
+	for id, s := range p.assignment {
+		if !wanted[id] {
+			s.assets = removeID(s.assets, id)
+			delete(p.assignment, id)
+			touched[s] = true
+		}
+	}
+
+	// Place new assets
+	for _, id := range ids {
+		if _, assigned := p.assignment[id]; assigned {
+			continue
+		}
+		s := p.shardWithCapacity()
+		s.assets = append(s.assets, id)
+		p.assignment[id] = s
+		touched[s] = true
+	}
+
+	var errs []error
+	kept := p.shards[:0]
+	for _, s := range p.shards {
+		if len(s.assets) == 0 {
+			p.closeShard(s)
+			continue
+		}
+		kept = append(kept, s)
+
+		if !touched[s] {
+			continue
+		}
+		assets := make([]string, len(s.assets))
+		copy(assets, s.assets)
+		if err := s.listener.UpdateAssetIDs(assets); err != nil {
+			errs = append(errs, fmt.Errorf("shard %s: %w", s.name, err))
+		}
+		if p.observer != nil {
+			p.observer.SetShardAssets(s.name, len(assets))
+		}
+	}
+	p.shards = kept
+
+	return errors.Join(errs...)
+}
+
+// shardWithCapacity returns the first shard with room, opening a new one if all are full.
+// Must be called with lock held.
+func (p *ListenerPool) shardWithCapacity() *shard {
+	for _, s := range p.shards {
+		if len(s.assets) < p.maxPerConn {
+			return s
+		}
+	}
+
+	s := &shard{name: fmt.Sprintf("%d", p.nextID)}
+	p.nextID++
+
+	s.listener = NewListener(p.url, p.sink)
+	s.listener.SetName(s.name)
+	if p.books != nil {
+		s.listener.SetBookSink(p.books)
+	}
+	if p.capture != nil {
+		s.listener.SetCapture(p.capture)
+	}
+	if p.observer != nil {
+		s.listener.SetObserver(shardConnectionObserver{shard: s.name, observer: p.observer})
+	}
+	if p.ctx != nil {
+		s.listener.Start(p.ctx)
+	}
+
+	p.shards = append(p.shards, s)
+	slog.Info("ws_shard_opened", "shard", s.name, "shards", len(p.shards))
+	return s
+}
+
+// closeShard stops an empty shard. Must be called with lock held.
+func (p *ListenerPool) closeShard(s *shard) {
+	if p.ctx != nil {
+		s.listener.Stop()
+	}
+	if p.observer != nil {
+		p.observer.RemoveShard(s.name)
+	}
+	slog.Info("ws_shard_closed", "shard", s.name)
+}
+
+// shardConnectionObserver adapts a ShardObserver to a single Listener.
+type shardConnectionObserver struct {
+	shard    string
+	observer ShardObserver
+}
+
+func (o shardConnectionObserver) SetWebSocketStatus(status string) {
+	o.observer.SetShardStatus(o.shard, status)
+}
+
+func (o shardConnectionObserver) IncrementReconnects() {
+	o.observer.IncrementShardReconnects(o.shard)
+}
+
+// removeID removes the first occurrence of id from ids.
+func removeID(ids []string, id string) []string {
+	for i, v := range ids {
+		if v == id {
+			return append(ids[:i], ids[i+1:]...)
+		}
+	}
+	return ids
+}
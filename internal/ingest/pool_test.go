@@ -0,0 +1,55 @@
+package ingest
+
+import (
+	"fmt"
+	"testing"
+)
+
+type fakeShardObserver struct {
+	assets  map[string]int
+	removed []string
+}
+
+func (f *fakeShardObserver) SetShardStatus(shard, status string)   {}
+func (f *fakeShardObserver) IncrementShardReconnects(shard string) {}
+func (f *fakeShardObserver) SetShardAssets(shard string, n int)    { f.assets[shard] = n }
+func (f *fakeShardObserver) RemoveShard(shard string) {
+	delete(f.assets, shard)
+	f.removed = append(f.removed, shard)
+}
+
+func TestListenerPoolSharding(t *testing.T) {
+	obs := &fakeShardObserver{assets: map[string]int{}}
+	pool := NewListenerPool("ws://unused", nil, 2)
+	pool.SetObserver(obs)
+
+	if err := pool.UpdateAssetIDs([]string{"a", "b", "c"}); err != nil {
+		t.Fatalf("UpdateAssetIDs failed: %v", err)
+	}
+	if pool.ShardCount() != 2 || fmt.Sprint(obs.assets) != "map[0:2 1:1]" {
+		t.Fatalf("Expected shards 0:2 1:1, got %d shards %v", pool.ShardCount(), obs.assets)
+	}
+
+	// Emptying shard 0 closes it; existing assets stay where they are
+	if err := pool.UpdateAssetIDs([]string{"c"}); err != nil {
+		t.Fatalf("UpdateAssetIDs failed: %v", err)
+	}
+	if pool.ShardCount() != 1 || fmt.Sprint(obs.removed) != "[0]" || obs.assets["1"] != 1 {
+		t.Fatalf("Expected only shard 1 with 1 asset, got %d shards %v removed %v", pool.ShardCount(), obs.assets, obs.removed)
+	}
+
+	// New assets fill existing capacity before opening a new shard
+	if err := pool.UpdateAssetIDs([]string{"c", "d", "e"}); err != nil {
+		t.Fatalf("UpdateAssetIDs failed: %v", err)
+	}
+	if pool.ShardCount() != 2 || obs.assets["1"] != 2 || obs.assets["2"] != 1 {
+		t.Errorf("Expected shards 1:2 2:1, got %v", obs.assets)
+	}
+
+	pool.shards[0].listener.assetIDsMu.RLock()
+	ids := fmt.Sprint(pool.shards[0].listener.assetIDs)
+	pool.shards[0].listener.assetIDsMu.RUnlock()
+	if ids != "[c d]" {
+		t.Errorf("Expected shard 1 listener to hold [c d], got %s", ids)
+	}
+}
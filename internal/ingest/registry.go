@@ -0,0 +1,148 @@
+package ingest
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+
+	"github.com/polyinsider/engine/internal/store"
+)
+
+// AssetInfo describes the market and outcome a CLOB token belongs to.
+type AssetInfo struct {
+	ConditionID string
+	MarketID    string // Gamma market ID
+	Outcome     string // YES/NO or the named outcome
+	Question    string
+	Slug        string
+	Volume      float64 // lifetime market volume in USD
+	Liquidity   float64 // order book liquidity in USD
+}
+
+// AssetRegistry maps CLOB token IDs to market metadata. It is filled from
+// the Gamma markets fetched by discovery.
+type AssetRegistry struct {
+	mu     sync.RWMutex
+	assets map[string]AssetInfo // token ID -> info
+}
+
+// NewAssetRegistry creates an empty registry.
+func NewAssetRegistry() *AssetRegistry {
+	return &AssetRegistry{
+		assets: make(map[string]AssetInfo),
+	}
+}
+
+// Replace rebuilds the registry from markets.
+func (r *AssetRegistry) Replace(markets []Market) {
+	assets := make(map[string]AssetInfo, len(markets)*2)
+	for _, market := range markets {
+		for tokenID, info := range assetInfos(market) {
+			assets[tokenID] = info
+		}
+	}
+
+	r.mu.Lock()
+	r.assets = assets
+	r.mu.Unlock()
+}
+
+// Merge adds or updates the assets of markets, keeping every other asset.
+func (r *AssetRegistry) Merge(markets []Market) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, market := range markets {
+		for tokenID, info := range assetInfos(market) {
+			r.assets[tokenID] = info
+		}
+	}
+}
+
+// Lookup returns the metadata for a token ID.
+func (r *AssetRegistry) Lookup(tokenID string) (AssetInfo, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	info, ok := r.assets[tokenID]
+	return info, ok
+}
+
+// Len returns the number of known tokens.
+func (r *AssetRegistry) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.assets)
+}
+
+// Enrich fills MarketID, Outcome, Question and MarketSlug on trade from its
+// asset ID. Fields already set by the source are kept.
+func (r *AssetRegistry) Enrich(trade *store.Trade) {
+	info, ok := r.Lookup(trade.AssetID)
+	if !ok {
+		return
+	}
+
+	if trade.MarketID == "" {
+		trade.MarketID = info.ConditionID
+	}
+	if trade.Outcome == "" {
+		trade.Outcome = info.Outcome
+	}
+	trade.Question = info.Question
+	trade.MarketSlug = info.Slug
+}
+
+// Wrap returns a TradeSink that enriches every trade before passing it to next.
+func (r *AssetRegistry) Wrap(next TradeSink) TradeSink {
+	return enrichingSink{registry: r, next: next}
+}
+
+// enrichingSink enriches trades from the registry before publishing them.
+type enrichingSink struct {
+	registry *AssetRegistry
+	next     TradeSink
+}
+
+func (s enrichingSink) Publish(trade store.Trade) {
+	s.registry.Enrich(&trade)
+	s.next.Publish(trade)
+}
+
+// assetInfos maps each token of market to its metadata. Gamma lists
+// clobTokenIds and outcomes in the same order.
+func assetInfos(market Market) map[string]AssetInfo {
+	var tokenIDs, outcomes []string
+	if err := json.Unmarshal([]byte(market.ClobTokenIDs), &tokenIDs); err != nil {
+		return nil
+	}
+	if market.Outcomes != "" {
+		_ = json.Unmarshal([]byte(market.Outcomes), &outcomes)
+	}
+
+	infos := make(map[string]AssetInfo, len(tokenIDs))
+	for i, tokenID := range tokenIDs {
+		outcome := ""
+		if i < len(outcomes) {
+			outcome = normalizeOutcome(outcomes[i])
+		}
+		infos[tokenID] = AssetInfo{
+			ConditionID: market.ConditionID,
+			MarketID:    market.ID,
+			Outcome:     outcome,
+			Question:    market.Question,
+			Slug:        market.Slug,
+			Volume:      market.VolumeNum,
+			Liquidity:   market.LiquidityNum,
+		}
+	}
+	return infos
+}
+
+// normalizeOutcome upper-cases binary outcomes and keeps named outcomes as-is.
+func normalizeOutcome(outcome string) string {
+	switch strings.ToUpper(outcome) {
+	case "YES", "NO":
+		return strings.ToUpper(outcome)
+	}
+	return outcome
+}
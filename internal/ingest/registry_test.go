@@ -0,0 +1,40 @@
+package ingest
+
+import (
+	"testing"
+
+	"github.com/polyinsider/engine/internal/store"
+)
+
+type tradeRecorder struct {
+	trades []store.Trade
+}
+
+func (r *tradeRecorder) Publish(trade store.Trade) {
+	r.trades = append(r.trades, trade)
+}
+
+func TestRegistryEnrichesTrades(t *testing.T) {
+	registry := NewAssetRegistry()
+	registry.Replace([]Market{
+		{ID: "1", ConditionID: "0xcond", Question: "Fed cuts?", Slug: "fed-cuts", ClobTokenIDs: `["yes-token","no-token"]`, Outcomes: `["Yes","No"]`},
+		{ID: "2", ConditionID: "0xelect", Question: "Who wins?", Slug: "who-wins", ClobTokenIDs: `["t-a"]`, Outcomes: `["Alice"]`},
+	})
+
+	out := &tradeRecorder{}
+	sink := registry.Wrap(out)
+	sink.Publish(store.Trade{AssetID: "no-token"})
+	sink.Publish(store.Trade{AssetID: "t-a", MarketID: "0xfromsource"})
+	sink.Publish(store.Trade{AssetID: "unknown"})
+
+	first := out.trades[0]
+	if first.MarketID != "0xcond" || first.Outcome != "NO" || first.Question != "Fed cuts?" || first.MarketSlug != "fed-cuts" {
+		t.Errorf("Unexpected enrichment %+v", first)
+	}
+	if second := out.trades[1]; second.MarketID != "0xfromsource" || second.Outcome != "Alice" {
+		t.Errorf("Expected source MarketID kept and named outcome, got %+v", second)
+	}
+	if third := out.trades[2]; third.Question != "" {
+		t.Errorf("Expected unknown asset untouched, got %+v", third)
+	}
+}
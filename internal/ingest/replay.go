@@ -0,0 +1,219 @@
+package ingest
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Replayer feeds capture files back through the frame parser and into the
+// same trade and book sinks as a live Listener.
+//
+// speed scales the recorded gaps between frames: 1 replays in real time,
+// 10 ten times faster, and 0 as fast as possible.
+type Replayer struct {
+	path     string
+	sink     TradeSink
+	books    BookSink
+	registry *AssetRegistry
+	speed    float64
+
+	frames  int
+	markets int
+}
+
+// NewReplayer creates a replayer for path, which is a capture file or a
+// directory of capture files replayed in name order.
+func NewReplayer(path string, sink TradeSink, speed float64) *Replayer {
+	if speed < 0 {
+		speed = 0
+	}
+	return &Replayer{
+		path:  path,
+		sink:  sink,
+		speed: speed,
+	}
+}
+
+// SetBookSink sets the sink that receives order book updates.
+func (r *Replayer) SetBookSink(books BookSink) {
+	r.books = books
+}
+
+// SetRegistry sets the asset registry rebuilt from recorded market snapshots.
+func (r *Replayer) SetRegistry(registry *AssetRegistry) {
+	r.registry = registry
+}
+
+// Run replays every capture file until done or ctx is cancelled.
+func (r *Replayer) Run(ctx context.Context) error {
+	files, err := captureFiles(r.path)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("replay_started", "path", r.path, "files", len(files), "speed", r.speed)
+	started := time.Now()
+
+	pacer := &replayPacer{speed: r.speed}
+	for _, file := range files {
+		if err := r.replayFile(ctx, file, pacer); err != nil {
+			return err
+		}
+	}
+
+	slog.Info("replay_finished",
+		"frames", r.frames,
+		"market_snapshots", r.markets,
+		"duration", time.Since(started),
+	)
+	return nil
+}
+
+// replayFile replays one capture file.
+func (r *Replayer) replayFile(ctx context.Context, path string, pacer *replayPacer) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open capture file: %w", err)
+	}
+	defer file.Close()
+
+	reader, err := captureReader(file)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	lines := bufio.NewReader(reader)
+	for {
+		line, readErr := lines.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			var record CaptureRecord
+			if err := json.Unmarshal(line, &record); err != nil {
+				return fmt.Errorf("invalid capture record in %s: %w", path, err)
+			}
+			if err := pacer.wait(ctx, record.Time); err != nil {
+				return err
+			}
+			r.apply(record)
+		}
+
+		if errors.Is(readErr, io.EOF) {
+			return nil
+		}
+		if readErr != nil {
+			return fmt.Errorf("failed to read %s: %w", path, readErr)
+		}
+	}
+}
+
+// apply dispatches one record.
+func (r *Replayer) apply(record CaptureRecord) {
+	if record.Markets != nil {
+		r.markets++
+		if r.registry != nil {
+			active := make([]Market, 0, len(record.Markets))
+			for _, market := range record.Markets {
+				if !market.Closed {
+					active = append(active, market)
+				}
+			}
+			r.registry.Replace(active)
+		}
+		return
+	}
+
+	if record.Frame != "" {
+		r.frames++
+		dispatchFrame([]byte(record.Frame), record.Time, r.sink, r.books)
+	}
+}
+
+// replayPacer sleeps so records are emitted with their recorded spacing
+// divided by speed.
+type replayPacer struct {
+	speed     float64
+	firstAt   time.Time // recorded time of the first record
+	startedAt time.Time // wall time the first record was replayed
+}
+
+// wait blocks until the record recorded at 'at' is due.
+func (p *replayPacer) wait(ctx context.Context, at time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if p.speed == 0 {
+		return nil
+	}
+	if p.firstAt.IsZero() {
+		p.firstAt, p.startedAt = at, time.Now()
+		return nil
+	}
+
+	due := p.startedAt.Add(time.Duration(float64(at.Sub(p.firstAt)) / p.speed))
+	delay := time.Until(due)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// captureReader returns a reader that transparently decompresses gzip files.
+func captureReader(r io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(r)
+	magic, err := buffered.Peek(2)
+	if err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		return gzip.NewReader(buffered)
+	}
+	return buffered, nil
+}
+
+// captureFiles resolves path to the list of files to replay.
+func captureFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open replay path: %w", err)
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list replay dir: %w", err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, "capture-") {
+			continue
+		}
+		files = append(files, filepath.Join(path, name))
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no capture files in %s", path)
+	}
+
+	// Names start with the UTC open time, so name order is capture order
+	sort.Strings(files)
+	return files, nil
+}
@@ -40,12 +40,12 @@ type TradesPoller struct {
 	baseURL   string
 	client    *http.Client
 	interval  time.Duration
-	tradeChan chan<- store.Trade
+	sink      TradeSink
 	lastPoll  time.Time
 }
 
-// NewTradesPoller creates a new TradesPoller.
-func NewTradesPoller(baseURL string, interval time.Duration, tradeChan chan<- store.Trade) *TradesPoller {
+// NewTradesPoller creates a new TradesPoller that publishes trades to sink.
+func NewTradesPoller(baseURL string, interval time.Duration, sink TradeSink) *TradesPoller {
 	if baseURL == "" {
 		baseURL = CLOBAPIBaseURL
 	}
@@ -57,7 +57,7 @@ func NewTradesPoller(baseURL string, interval time.Duration, tradeChan chan<- st
 		baseURL:   baseURL,
 		client:    &http.Client{Timeout: 10 * time.Second},
 		interval:  interval,
-		tradeChan: tradeChan,
+		sink:      sink,
 		lastPoll:  time.Now().Add(-5 * time.Minute), // Start with 5 min lookback
 	}
 }
@@ -87,7 +87,7 @@ func (p *TradesPoller) Start(ctx context.Context) {
 	}
 }
 
-// poll fetches recent trades and sends them to the trade channel.
+// poll fetches recent trades and publishes them to the sink.
 func (p *TradesPoller) poll(ctx context.Context) error {
 	trades, err := p.fetchRecentTrades(ctx, p.lastPoll)
 	if err != nil {
@@ -99,12 +99,7 @@ func (p *TradesPoller) poll(ctx context.Context) error {
 		p.lastPoll = time.Now()
 
 		for _, trade := range trades {
-			select {
-			case p.tradeChan <- trade:
-				// Successfully sent
-			default:
-				slog.Warn("trade_channel_full_api", "dropped_trade", trade.ID)
-			}
+			p.sink.Publish(trade)
 		}
 	}
 
@@ -157,11 +152,7 @@ func (p *TradesPoller) fetchRecentTrades(ctx context.Context, after time.Time) (
 
 // convertTrade converts a TradeAPIResponse to store.Trade.
 func (p *TradesPoller) convertTrade(apiTrade TradeAPIResponse) store.Trade {
-	price := parseFloatSafe(apiTrade.Price)
-	size := parseFloatSafe(apiTrade.Size)
-	valueUSD := price * size // Simplified calculation
-
-	return store.Trade{
+	trade := store.Trade{
 		ID:              fmt.Sprintf("api-%s", apiTrade.ID),
 		MarketID:        apiTrade.Market,
 		AssetID:         apiTrade.AssetID,
@@ -170,12 +161,16 @@ func (p *TradesPoller) convertTrade(apiTrade TradeAPIResponse) store.Trade {
 		Side:            apiTrade.Side,
 		Outcome:         apiTrade.Outcome,
 		Size:            apiTrade.Size,
-		Price:           price,
-		ValueUSD:        valueUSD,
+		Price:           parseFloatSafe(apiTrade.Price),
 		Timestamp:       time.UnixMilli(apiTrade.Timestamp),
 		TradeID:         apiTrade.TradeID,
 		TransactionHash: apiTrade.TransactionHash,
+		Source:          store.SourceREST,
 	}
+
+	// The CLOB trades endpoint reports size in shares
+	ValueTrade(&trade, Shares)
+	return trade
 }
 
 // parseFloatSafe safely parses a string to float64, returning 0 on error.
@@ -0,0 +1,117 @@
+package ingest
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/polyinsider/engine/internal/store"
+)
+
+// Denomination is the unit a source reports trade size in.
+type Denomination int
+
+const (
+	// Shares is a decimal number of outcome tokens (CLOB WebSocket and REST).
+	Shares Denomination = iota
+	// RawShares is an integer number of outcome token base units (6 decimals, on-chain).
+	RawShares
+	// USDC is a decimal USDC amount already equal to the notional.
+	USDC
+	// RawUSDC is an integer number of USDC base units (6 decimals, on-chain).
+	RawUSDC
+)
+
+// usdcDecimals is the scale of USDC and CTF outcome token base units on Polygon.
+const usdcDecimals = 1e6
+
+// Valuation methods recorded on store.Trade.ValuationMethod.
+const (
+	ValuationShares    = "shares_x_price"
+	ValuationRawShares = "raw_shares_x_price"
+	ValuationUSDC      = "usdc"
+	ValuationRawUSDC   = "raw_usdc"
+	ValuationNoSize    = "no_size"
+	ValuationBadSize   = "invalid_size"
+	ValuationBadPrice  = "invalid_price"
+)
+
+// String returns the denomination name.
+func (d Denomination) String() string {
+	switch d {
+	case RawShares:
+		return "raw_shares"
+	case USDC:
+		return "usdc"
+	case RawUSDC:
+		return "raw_usdc"
+	default:
+		return "shares"
+	}
+}
+
+// ValueTrade sets ValueUSD and ValuationMethod on trade from its Size and
+// Price, with Size interpreted in denom.
+//
+// ValueUSD is the USDC notional of the fill for the traded outcome token:
+// the cost paid on a BUY and the proceeds received on a SELL. Both are
+// shares x price of that token, so a NO fill is valued at the NO price,
+// never at the complementary YES price. The suffix of ValuationMethod
+// records which side the value refers to.
+func ValueTrade(trade *store.Trade, denom Denomination) {
+	value, method := Valuate(trade.Size, trade.Price, denom)
+	trade.ValueUSD = value
+	trade.ValuationMethod = method + sideSuffix(trade.Side)
+}
+
+// Valuate computes the USD notional of size at price in the given denomination.
+func Valuate(size string, price float64, denom Denomination) (float64, string) {
+	size = strings.TrimSpace(size)
+	if size == "" {
+		return 0, ValuationNoSize
+	}
+
+	switch denom {
+	case USDC:
+		amount, err := strconv.ParseFloat(size, 64)
+		if err != nil || amount < 0 {
+			return 0, ValuationBadSize
+		}
+		return amount, ValuationUSDC
+
+	case RawUSDC:
+		units, err := strconv.ParseUint(size, 10, 64)
+		if err != nil {
+			return 0, ValuationBadSize
+		}
+		return float64(units) / usdcDecimals, ValuationRawUSDC
+	}
+
+	if price <= 0 || price > 1 {
+		return 0, ValuationBadPrice
+	}
+
+	if denom == RawShares {
+		units, err := strconv.ParseUint(size, 10, 64)
+		if err != nil {
+			return 0, ValuationBadSize
+		}
+		return float64(units) / usdcDecimals * price, ValuationRawShares
+	}
+
+	shares, err := strconv.ParseFloat(size, 64)
+	if err != nil || shares < 0 {
+		return 0, ValuationBadSize
+	}
+	return shares * price, ValuationShares
+}
+
+// sideSuffix returns ":buy" or ":sell" for a trade side, or "" if unknown.
+func sideSuffix(side string) string {
+	switch strings.ToUpper(side) {
+	case "BUY":
+		return ":buy"
+	case "SELL":
+		return ":sell"
+	}
+	return ""
+}
@@ -0,0 +1,35 @@
+package ingest
+
+import (
+	"math"
+	"testing"
+
+	"github.com/polyinsider/engine/internal/store"
+)
+
+func TestValuation(t *testing.T) {
+	cases := []struct {
+		name   string
+		trade  store.Trade
+		denom  Denomination
+		value  float64
+		method string
+	}{
+		{"buy shares", store.Trade{Side: "BUY", Size: "1000", Price: 0.25}, Shares, 250, "shares_x_price:buy"},
+		{"sell shares", store.Trade{Side: "SELL", Size: "1000", Price: 0.25}, Shares, 250, "shares_x_price:sell"},
+		{"large share count is not rescaled", store.Trade{Side: "BUY", Size: "2000000", Price: 0.5}, Shares, 1000000, "shares_x_price:buy"},
+		{"raw shares", store.Trade{Side: "BUY", Size: "2000000", Price: 0.5}, RawShares, 1, "raw_shares_x_price:buy"},
+		{"raw usdc", store.Trade{Size: "1500000000"}, RawUSDC, 1500, "raw_usdc"},
+		{"raw with decimals", store.Trade{Size: "1.5", Price: 0.5}, RawShares, 0, "invalid_size"},
+		{"price out of range", store.Trade{Size: "10", Price: 1.5}, Shares, 0, "invalid_price"},
+		{"no size", store.Trade{Price: 0.5}, Shares, 0, "no_size"},
+	}
+
+	for _, c := range cases {
+		trade := c.trade
+		ValueTrade(&trade, c.denom)
+		if math.Abs(trade.ValueUSD-c.value) > 1e-9 || trade.ValuationMethod != c.method {
+			t.Errorf("%s: got %v %q, want %v %q", c.name, trade.ValueUSD, trade.ValuationMethod, c.value, c.method)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package ingest
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"math/rand"
@@ -12,6 +13,7 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/polyinsider/engine/internal/orderbook"
 	"github.com/polyinsider/engine/internal/store"
 )
 
@@ -30,10 +32,32 @@ const (
 	WriteTimeout = 10 * time.Second
 )
 
+// TradeSink receives trades produced by an ingest source.
+type TradeSink interface {
+	Publish(trade store.Trade)
+}
+
+// BookSink receives order book snapshots and level changes.
+type BookSink interface {
+	ApplySnapshot(snapshot orderbook.Snapshot) error
+	ApplyChange(change orderbook.PriceChange) error
+}
+
+// ConnectionObserver receives WebSocket connection lifecycle updates.
+type ConnectionObserver interface {
+	SetWebSocketStatus(status string)
+	IncrementReconnects()
+}
+
 // Listener manages WebSocket connection to Polymarket.
 type Listener struct {
 	url        string
-	tradeChan  chan<- store.Trade
+	name       string // shard name for logging
+	sink       TradeSink
+	books      BookSink
+	capture    FrameRecorder
+	observer   ConnectionObserver
+	connected  bool // true once a connection has been established (for reconnect counting)
 	conn       *websocket.Conn
 	connMu     sync.Mutex
 	backoff    time.Duration
@@ -45,11 +69,12 @@ type Listener struct {
 	assetIDsMu sync.RWMutex
 }
 
-// NewListener creates a new WebSocket listener.
-func NewListener(url string, tradeChan chan<- store.Trade) *Listener {
+// NewListener creates a new WebSocket listener that publishes trades to sink.
+func NewListener(url string, sink TradeSink) *Listener {
 	return &Listener{
 		url:       url,
-		tradeChan: tradeChan,
+		name:      "0",
+		sink:      sink,
 		backoff:   InitialBackoff,
 		stopChan:  make(chan struct{}),
 		assetIDs:  []string{},
@@ -63,6 +88,58 @@ func (l *Listener) SetAssetIDs(ids []string) {
 	l.assetIDs = ids
 }
 
+// UpdateAssetIDs replaces the subscribed asset set. When connected, only the
+// difference is sent as subscribe/unsubscribe operations on the live connection.
+func (l *Listener) UpdateAssetIDs(ids []string) error {
+	l.assetIDsMu.Lock()
+	added, removed := diffIDs(l.assetIDs, ids)
+	l.assetIDs = ids
+	l.assetIDsMu.Unlock()
+
+	if len(added) == 0 && len(removed) == 0 {
+		return nil
+	}
+
+	// Not connected: the next connect subscribes to the full set
+	l.connMu.Lock()
+	connected := l.conn != nil
+	l.connMu.Unlock()
+	if !connected {
+		return nil
+	}
+
+	if err := l.sendOperation("subscribe", added); err != nil {
+		l.closeConnection() // reconnect resubscribes the full set
+		return err
+	}
+	if err := l.sendOperation("unsubscribe", removed); err != nil {
+		l.closeConnection()
+		return err
+	}
+
+	return nil
+}
+
+// SetName sets the connection name used in logs.
+func (l *Listener) SetName(name string) {
+	l.name = name
+}
+
+// SetBookSink sets the sink that receives order book updates.
+func (l *Listener) SetBookSink(books BookSink) {
+	l.books = books
+}
+
+// SetCapture sets the recorder that receives every raw frame.
+func (l *Listener) SetCapture(capture FrameRecorder) {
+	l.capture = capture
+}
+
+// SetObserver sets the observer notified of connection status changes.
+func (l *Listener) SetObserver(o ConnectionObserver) {
+	l.observer = o
+}
+
 // Start begins the WebSocket listener with automatic reconnection.
 func (l *Listener) Start(ctx context.Context) {
 	l.wg.Add(1)
@@ -86,24 +163,24 @@ func (l *Listener) runLoop(ctx context.Context) {
 	for {
 		select {
 		case <-ctx.Done():
-			slog.Info("ws_loop_stopping", "reason", "context cancelled")
+			slog.Info("ws_loop_stopping", "conn", l.name, "reason", "context cancelled")
 			return
 		case <-l.stopChan:
-			slog.Info("ws_loop_stopping", "reason", "stop signal")
+			slog.Info("ws_loop_stopping", "conn", l.name, "reason", "stop signal")
 			return
 		default:
 		}
 
 		// Attempt connection
 		if err := l.connect(ctx); err != nil {
-			slog.Error("ws_connect_failed", "error", err, "backoff", l.backoff)
+			slog.Error("ws_connect_failed", "conn", l.name, "error", err, "backoff", l.backoff)
 			l.waitBackoff(ctx)
 			continue
 		}
 
 		// Read messages until error
 		if err := l.readLoop(ctx); err != nil {
-			slog.Warn("ws_read_error", "error", err)
+			slog.Warn("ws_read_error", "conn", l.name, "error", err)
 		}
 
 		l.closeConnection()
@@ -150,7 +227,15 @@ func (l *Listener) connect(ctx context.Context) error {
 	// Reset backoff on successful connection
 	l.backoff = InitialBackoff
 
-	slog.Info("ws_connected", "endpoint", url)
+	if l.observer != nil {
+		if l.connected {
+			l.observer.IncrementReconnects()
+		}
+		l.observer.SetWebSocketStatus("connected")
+	}
+	l.connected = true
+
+	slog.Info("ws_connected", "conn", l.name, "endpoint", url)
 
 	// Subscribe to market channel
 	// Note: Empty assets_ids may subscribe to all, or we may need to fetch market IDs
@@ -176,22 +261,49 @@ func (l *Listener) subscribe() error {
 		"assets_ids": assetIDs,
 	}
 
-	l.connMu.Lock()
-	defer l.connMu.Unlock()
+	if err := l.write(func(conn *websocket.Conn) error { return conn.WriteJSON(msg) }); err != nil {
+		return fmt.Errorf("failed to send subscribe message: %w", err)
+	}
 
-	if l.conn == nil {
-		return fmt.Errorf("connection is nil")
+	slog.Info("ws_subscribed", "conn", l.name, "channel", "market", "asset_count", len(assetIDs))
+	return nil
+}
+
+// sendOperation sends a dynamic subscribe or unsubscribe for assetIDs.
+func (l *Listener) sendOperation(operation string, assetIDs []string) error {
+	if len(assetIDs) == 0 {
+		return nil
 	}
 
-	l.conn.SetWriteDeadline(time.Now().Add(WriteTimeout))
-	if err := l.conn.WriteJSON(msg); err != nil {
-		return fmt.Errorf("failed to send subscribe message: %w", err)
+	msg := map[string]interface{}{
+		"assets_ids": assetIDs,
+		"operation":  operation,
+	}
+
+	if err := l.write(func(conn *websocket.Conn) error { return conn.WriteJSON(msg) }); err != nil {
+		return fmt.Errorf("failed to send %s message: %w", operation, err)
 	}
 
-	slog.Info("ws_subscribed", "channel", "market", "asset_count", len(assetIDs))
+	slog.Info("ws_subscription_updated", "conn", l.name, "operation", operation, "asset_count", len(assetIDs))
 	return nil
 }
 
+// errNotConnected is returned by writes while no connection is open.
+var errNotConnected = errors.New("connection is nil")
+
+// write runs fn against the live connection with connMu held. A gorilla
+// connection supports one writer at a time, so every write goes through here.
+func (l *Listener) write(fn func(conn *websocket.Conn) error) error {
+	l.connMu.Lock()
+	defer l.connMu.Unlock()
+
+	if l.conn == nil {
+		return errNotConnected
+	}
+	l.conn.SetWriteDeadline(time.Now().Add(WriteTimeout))
+	return fn(l.conn)
+}
+
 // readLoop reads messages from the WebSocket.
 func (l *Listener) readLoop(ctx context.Context) error {
 	for {
@@ -220,20 +332,36 @@ func (l *Listener) readLoop(ctx context.Context) error {
 		}
 
 		l.updateLastMsg()
+		received := time.Now()
+
+		if l.capture != nil {
+			l.capture.RecordFrame(l.name, message, received)
+		}
 
 		// Parse and dispatch trades
-		l.handleMessage(message)
+		l.handleMessage(message, received)
 	}
 }
 
-// handleMessage parses a message and dispatches trades.
-func (l *Listener) handleMessage(data []byte) {
-	trades, msgType, err := ParseMessage(data)
+// handleMessage parses a message, applies book updates and dispatches trades.
+func (l *Listener) handleMessage(data []byte, received time.Time) {
+	dispatchFrame(data, received, l.sink, l.books)
+}
+
+// dispatchFrame parses a raw frame received at the given time, applies book
+// updates and publishes trades. Live listeners and replay share it so both
+// feed the pipeline identically.
+func dispatchFrame(data []byte, received time.Time, sink TradeSink, books BookSink) {
+	trades, bookEvents, msgType, err := parseMessage(data, received)
 	if err != nil {
 		slog.Debug("ws_parse_error", "error", err, "raw", string(data))
 		return
 	}
 
+	if books != nil && len(bookEvents) > 0 {
+		applyBookUpdates(books, bookEvents)
+	}
+
 	// Log non-trade messages at debug level
 	if len(trades) == 0 {
 		if msgType != "" {
@@ -242,19 +370,31 @@ func (l *Listener) handleMessage(data []byte) {
 		return
 	}
 
-	// Dispatch trades to channel
+	// Dispatch trades to sink
 	for _, trade := range trades {
-		select {
-		case l.tradeChan <- trade:
-			slog.Debug("trade_received",
-				"market", truncate(trade.MarketID, 16),
-				"maker", truncate(trade.MakerAddress, 10),
-				"size", trade.Size,
-				"price", trade.Price,
-				"value_usd", trade.ValueUSD,
-			)
-		default:
-			slog.Warn("trade_channel_full", "dropped_trade", trade.ID)
+		trade.Source = store.SourceWebSocket
+		sink.Publish(trade)
+		slog.Debug("trade_received",
+			"market", truncate(trade.MarketID, 16),
+			"maker", truncate(trade.MakerAddress, 10),
+			"size", trade.Size,
+			"price", trade.Price,
+			"value_usd", trade.ValueUSD,
+		)
+	}
+}
+
+// applyBookUpdates forwards book snapshots and level changes to the book sink.
+func applyBookUpdates(books BookSink, events []BookEvent) {
+	snapshots, changes := bookUpdates(events)
+	for _, snapshot := range snapshots {
+		if err := books.ApplySnapshot(snapshot); err != nil {
+			slog.Debug("book_snapshot_error", "asset", truncate(snapshot.AssetID, 16), "error", err)
+		}
+	}
+	for _, change := range changes {
+		if err := books.ApplyChange(change); err != nil {
+			slog.Debug("book_change_error", "asset", truncate(change.AssetID, 16), "error", err)
 		}
 	}
 }
@@ -290,19 +430,13 @@ func (l *Listener) checkHeartbeat() {
 
 	elapsed := time.Since(lastMsg)
 	if elapsed > HeartbeatTimeout {
-		slog.Warn("ws_heartbeat_timeout", "elapsed", elapsed)
+		slog.Warn("ws_heartbeat_timeout", "conn", l.name, "elapsed", elapsed)
 
 		// Send ping
-		l.connMu.Lock()
-		conn := l.conn
-		l.connMu.Unlock()
-
-		if conn != nil {
-			conn.SetWriteDeadline(time.Now().Add(WriteTimeout))
-			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
-				slog.Warn("ws_ping_failed", "error", err)
-				l.closeConnection()
-			}
+		err := l.write(func(conn *websocket.Conn) error { return conn.WriteMessage(websocket.PingMessage, nil) })
+		if err != nil && !errors.Is(err, errNotConnected) {
+			slog.Warn("ws_ping_failed", "error", err)
+			l.closeConnection()
 		}
 	}
 }
@@ -322,7 +456,10 @@ func (l *Listener) closeConnection() {
 	if l.conn != nil {
 		l.conn.Close()
 		l.conn = nil
-		slog.Info("ws_disconnected")
+		slog.Info("ws_disconnected", "conn", l.name)
+		if l.observer != nil {
+			l.observer.SetWebSocketStatus("disconnected")
+		}
 	}
 }
 
@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultLatencyBuckets are histogram upper bounds in seconds, tuned for
+// RPC calls (~50-200ms) and in-process detection (sub-millisecond).
+var DefaultLatencyBuckets = []float64{0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5}
+
+// Histogram is a thread-safe cumulative histogram of durations.
+type Histogram struct {
+	mu      sync.Mutex
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+// HistogramSnapshot is a point-in-time copy of a histogram.
+type HistogramSnapshot struct {
+	Buckets []float64 // upper bounds in seconds
+	Counts  []uint64  // cumulative count per bucket
+	Sum     float64   // sum of observations in seconds
+	Count   uint64
+}
+
+// NewHistogram creates a histogram with the given bucket upper bounds (seconds, ascending).
+func NewHistogram(buckets []float64) *Histogram {
+	return &Histogram{
+		buckets: buckets,
+		counts:  make([]uint64, len(buckets)),
+	}
+}
+
+// Observe records a duration.
+func (h *Histogram) Observe(d time.Duration) {
+	seconds := d.Seconds()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for i, bound := range h.buckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+// Snapshot returns a copy of the histogram state.
+func (h *Histogram) Snapshot() HistogramSnapshot {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	counts := make([]uint64, len(h.counts))
+	copy(counts, h.counts)
+
+	return HistogramSnapshot{
+		Buckets: h.buckets,
+		Counts:  counts,
+		Sum:     h.sum,
+		Count:   h.count,
+	}
+}
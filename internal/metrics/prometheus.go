@@ -0,0 +1,195 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// metricPrefix is prepended to every exported metric name.
+const metricPrefix = "polyinsider_"
+
+// Handler returns an http.Handler serving the tracker in Prometheus text exposition format.
+func Handler(m *MetricsTracker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		WritePrometheus(w, m.Snapshot())
+	})
+}
+
+// StartServer serves /metrics on port until ctx is cancelled.
+func StartServer(ctx context.Context, port int, m *MetricsTracker) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler(m))
+
+	srv := &http.Server{
+		Addr:              fmt.Sprintf(":%d", port),
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		slog.Info("metrics_server_started", "addr", srv.Addr, "path", "/metrics")
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("metrics_server_failed", "error", err)
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	return srv
+}
+
+// WritePrometheus writes a snapshot in Prometheus text exposition format (spec Section 8.2).
+func WritePrometheus(w io.Writer, s MetricsSnapshot) {
+	// Counters
+	writeHeader(w, "trades_received_total", "counter", "Trades received from all ingest sources.")
+	writeSample(w, "trades_received_total", nil, float64(s.TradesTotal))
+
+	writeHeader(w, "high_value_trades_total", "counter", "Trades at or above MIN_VALUE_USD.")
+	writeSample(w, "high_value_trades_total", nil, float64(s.HighValueTrades))
+
+	writeHeader(w, "signals_detected_total", "counter", "Detection signals by type.")
+	for _, signal := range sortedKeys(s.SignalsByType) {
+		writeSample(w, "signals_detected_total", []string{"type", signal}, float64(s.SignalsByType[signal]))
+	}
+
+	writeHeader(w, "trades_dropped_total", "counter", "Trades dropped by the event bus per subscriber.")
+	for _, sub := range sortedKeys(s.DroppedTrades) {
+		writeSample(w, "trades_dropped_total", []string{"subscriber", sub}, float64(s.DroppedTrades[sub]))
+	}
+
+	writeHeader(w, "trades_duplicates_total", "counter", "Trades dropped as cross-source duplicates per source.")
+	for _, source := range sortedKeys(s.DuplicateTrades) {
+		writeSample(w, "trades_duplicates_total", []string{"source", source}, float64(s.DuplicateTrades[source]))
+	}
+
+	writeHeader(w, "websocket_reconnects_total", "counter", "WebSocket reconnections across all shards.")
+	writeSample(w, "websocket_reconnects_total", nil, float64(s.WSReconnects))
+
+	writeHeader(w, "websocket_shard_reconnects_total", "counter", "WebSocket reconnections per shard.")
+	for _, shard := range sortedKeys(s.WSShards) {
+		writeSample(w, "websocket_shard_reconnects_total", []string{"shard", shard}, float64(s.WSShards[shard].Reconnects))
+	}
+
+	writeHeader(w, "rpc_calls_total", "counter", "Enrichment RPC calls by status.")
+	for _, status := range sortedKeys(s.RPCCalls) {
+		writeSample(w, "rpc_calls_total", []string{"status", status}, float64(s.RPCCalls[status]))
+	}
+
+	writeHeader(w, "markets_added_total", "counter", "Markets added to the subscription by discovery.")
+	writeSample(w, "markets_added_total", nil, float64(s.MarketsAdded))
+
+	writeHeader(w, "markets_closed_total", "counter", "Markets removed from the subscription by discovery.")
+	writeSample(w, "markets_closed_total", nil, float64(s.MarketsClosed))
+
+	// Gauges
+	writeHeader(w, "markets_active", "gauge", "Markets currently subscribed.")
+	writeSample(w, "markets_active", nil, float64(s.ActiveMarkets))
+
+	connected := 0.0
+	if s.WebSocketStatus == "connected" {
+		connected = 1
+	}
+	writeHeader(w, "websocket_connected", "gauge", "1 if every WebSocket shard is connected.")
+	writeSample(w, "websocket_connected", []string{"endpoint", "polymarket"}, connected)
+
+	writeHeader(w, "websocket_shard_connected", "gauge", "1 if the WebSocket shard is connected.")
+	for _, shard := range sortedKeys(s.WSShards) {
+		up := 0.0
+		if s.WSShards[shard].Status == "connected" {
+			up = 1
+		}
+		writeSample(w, "websocket_shard_connected", []string{"shard", shard}, up)
+	}
+
+	writeHeader(w, "websocket_shard_assets", "gauge", "Assets subscribed per WebSocket shard.")
+	for _, shard := range sortedKeys(s.WSShards) {
+		writeSample(w, "websocket_shard_assets", []string{"shard", shard}, float64(s.WSShards[shard].Assets))
+	}
+
+	writeHeader(w, "channel_buffer_used", "gauge", "Trades buffered for the worker pool.")
+	writeSample(w, "channel_buffer_used", nil, float64(s.ChannelBufferUsed))
+
+	writeHeader(w, "channel_buffer_capacity", "gauge", "Capacity of the worker pool trade buffer.")
+	writeSample(w, "channel_buffer_capacity", nil, float64(s.ChannelBufferCap))
+
+	writeHeader(w, "worker_queue_depth", "gauge", "Trades buffered per worker shard.")
+	for _, worker := range sortedKeys(s.WorkerQueues) {
+		writeSample(w, "worker_queue_depth", []string{"worker", worker}, float64(s.WorkerQueues[worker].Used))
+	}
+
+	writeHeader(w, "worker_queue_capacity", "gauge", "Buffer capacity per worker shard.")
+	for _, worker := range sortedKeys(s.WorkerQueues) {
+		writeSample(w, "worker_queue_capacity", []string{"worker", worker}, float64(s.WorkerQueues[worker].Capacity))
+	}
+
+	writeHeader(w, "uptime_seconds", "gauge", "Seconds since the engine started.")
+	writeSample(w, "uptime_seconds", nil, s.Uptime.Seconds())
+
+	// Histograms
+	writeHistogram(w, "rpc_latency_seconds", "Enrichment RPC latency.", s.RPCLatency)
+	writeHistogram(w, "detection_latency_seconds", "Time to enrich and run detection on a trade.", s.DetectionLatency)
+}
+
+// writeHeader writes the HELP and TYPE lines for a metric.
+func writeHeader(w io.Writer, name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %s%s %s\n", metricPrefix, name, help)
+	fmt.Fprintf(w, "# TYPE %s%s %s\n", metricPrefix, name, kind)
+}
+
+// writeSample writes a single sample line. labels is a flat list of name/value pairs.
+func writeSample(w io.Writer, name string, labels []string, value float64) {
+	fmt.Fprintf(w, "%s%s%s %s\n", metricPrefix, name, formatLabels(labels), formatValue(value))
+}
+
+// writeHistogram writes the bucket, sum and count series for a histogram.
+func writeHistogram(w io.Writer, name, help string, h HistogramSnapshot) {
+	writeHeader(w, name, "histogram", help)
+	for i, bound := range h.Buckets {
+		writeSample(w, name+"_bucket", []string{"le", formatValue(bound)}, float64(h.Counts[i]))
+	}
+	writeSample(w, name+"_bucket", []string{"le", "+Inf"}, float64(h.Count))
+	writeSample(w, name+"_sum", nil, h.Sum)
+	writeSample(w, name+"_count", nil, float64(h.Count))
+}
+
+// formatLabels renders {name="value",...}.
+func formatLabels(labels []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(labels)/2)
+	for i := 0; i+1 < len(labels); i += 2 {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", labels[i], labels[i+1]))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// formatValue renders a float the way Prometheus expects.
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// sortedKeys returns map keys in sorted order for stable output.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/polyinsider/engine/internal/clock"
+)
+
+func TestPrometheusExposition(t *testing.T) {
+	m := NewMetricsTracker(clock.Real{})
+	m.IncrementTrades()
+	m.IncrementSignal("WHALE")
+	m.IncrementReconnects()
+	m.SetDroppedTrades("workers-0", 7)
+	m.SetWorkerQueue("workers-1", 12, 100)
+	m.ObserveRPC(120*time.Millisecond, true)
+	m.ObserveRPC(3*time.Second, false)
+
+	rec := httptest.NewRecorder()
+	Handler(m).ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+
+	for _, want := range []string{
+		"# TYPE polyinsider_trades_received_total counter",
+		"polyinsider_trades_received_total 1",
+		`polyinsider_signals_detected_total{type="WHALE"} 1`,
+		`polyinsider_trades_dropped_total{subscriber="workers-0"} 7`,
+		`polyinsider_worker_queue_depth{worker="workers-1"} 12`,
+		"polyinsider_websocket_reconnects_total 1",
+		`polyinsider_rpc_calls_total{status="error"} 1`,
+		`polyinsider_rpc_latency_seconds_bucket{le="0.25"} 1`,
+		`polyinsider_rpc_latency_seconds_bucket{le="+Inf"} 2`,
+		"polyinsider_rpc_latency_seconds_count 2",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected exposition to contain %q", want)
+		}
+	}
+}
@@ -2,14 +2,22 @@
 package metrics
 
 import (
+	"sort"
 	"sync"
 	"time"
+
+	"github.com/polyinsider/engine/internal/clock"
+	"github.com/polyinsider/engine/internal/store"
 )
 
+// PriceHistoryWindow is the event-time span of price history kept per market.
+const PriceHistoryWindow = 60 * time.Minute
+
 // PricePoint represents a price at a specific time.
 type PricePoint struct {
 	Price     float64
 	Timestamp time.Time
+	ValueUSD  float64 // trade value; only set on market activity points
 }
 
 // MarketActivity tracks activity for a single market.
@@ -23,6 +31,14 @@ type MarketActivity struct {
 	LastUpdate  time.Time
 }
 
+// ShardHealth is the connection health of one WebSocket shard.
+type ShardHealth struct {
+	Status     string
+	Assets     int
+	Reconnects int64
+	LastChange time.Time
+}
+
 // MetricsSnapshot is a point-in-time view of metrics.
 type MetricsSnapshot struct {
 	TradesTotal       int64
@@ -36,6 +52,23 @@ type MetricsSnapshot struct {
 	RESTAPILastPoll   time.Time
 	ChannelBufferUsed int
 	ChannelBufferCap  int
+	WSReconnects      int64
+	WSShards          map[string]ShardHealth // shard name -> health
+	DroppedTrades     map[string]uint64 // subscriber -> dropped count
+	DuplicateTrades   map[string]int64  // source -> duplicates dropped
+	WorkerQueues      map[string]QueueDepth // worker shard -> buffered trades
+	RPCCalls          map[string]int64  // status -> count
+	RPCLatency        HistogramSnapshot
+	DetectionLatency  HistogramSnapshot
+	MarketsAdded      int64
+	MarketsClosed     int64
+	ActiveMarkets     int
+}
+
+// QueueDepth is the buffer usage of one worker shard.
+type QueueDepth struct {
+	Used     int
+	Capacity int
 }
 
 // MoverStats represents a market with significant activity.
@@ -49,8 +82,12 @@ type MoverStats struct {
 }
 
 // MetricsTracker provides thread-safe metrics tracking.
+// Trade rate and uptime use the clock; price history and market activity
+// are windowed by trade event time.
 type MetricsTracker struct {
 	mu                sync.RWMutex
+	clock             clock.Clock
+	events            *clock.Watermark // event-time progress of recorded trades
 	tradesTotal       int64
 	highValueTrades   int64
 	signalsByType     map[string]int64
@@ -63,17 +100,38 @@ type MetricsTracker struct {
 	restLastPoll      time.Time
 	channelBufferUsed int
 	channelBufferCap  int
+	wsReconnects      int64
+	wsShards          map[string]*ShardHealth
+	droppedTrades     map[string]uint64
+	duplicateTrades   map[string]int64
+	workerQueues      map[string]QueueDepth
+	rpcCalls          map[string]int64
+	rpcLatency        *Histogram
+	detectionLatency  *Histogram
+	marketsAdded      int64
+	marketsClosed     int64
+	activeMarkets     map[string]bool // market IDs currently subscribed
 }
 
-// NewMetricsTracker creates a new MetricsTracker.
-func NewMetricsTracker() *MetricsTracker {
+// NewMetricsTracker creates a new MetricsTracker that reads time from clk.
+func NewMetricsTracker(clk clock.Clock) *MetricsTracker {
 	return &MetricsTracker{
+		clock:           clk,
+		events:          clock.NewWatermark(clock.DefaultLateness),
 		signalsByType:   make(map[string]int64),
 		priceHistory:    make(map[string][]PricePoint),
 		marketActivity:  make(map[string]*MarketActivity),
-		startTime:       time.Now(),
-		tradeTimestamps: make([]time.Time, 0, 1000),
-		wsStatus:        "disconnected",
+		startTime:       clk.Now(),
+		tradeTimestamps:  make([]time.Time, 0, 1000),
+		wsStatus:         "disconnected",
+		wsShards:         make(map[string]*ShardHealth),
+		droppedTrades:    make(map[string]uint64),
+		duplicateTrades:  make(map[string]int64),
+		workerQueues:     make(map[string]QueueDepth),
+		rpcCalls:         make(map[string]int64),
+		rpcLatency:       NewHistogram(DefaultLatencyBuckets),
+		detectionLatency: NewHistogram(DefaultLatencyBuckets),
+		activeMarkets:    make(map[string]bool),
 	}
 }
 
@@ -83,7 +141,7 @@ func (m *MetricsTracker) IncrementTrades() {
 	defer m.mu.Unlock()
 	
 	m.tradesTotal++
-	m.lastTradeTime = time.Now()
+	m.lastTradeTime = m.clock.Now()
 	
 	// Add to timestamps for rate calculation
 	m.tradeTimestamps = append(m.tradeTimestamps, m.lastTradeTime)
@@ -116,36 +174,24 @@ func (m *MetricsTracker) IncrementSignal(signalType string) {
 	m.signalsByType[signalType]++
 }
 
-// RecordPrice records a price point for a market.
-func (m *MetricsTracker) RecordPrice(marketID string, price float64) {
+// RecordPrice records a price point for a market at the trade's event time.
+func (m *MetricsTracker) RecordPrice(marketID string, price float64, at time.Time) {
+	at = clock.EventTime(at, m.clock)
+	m.events.Observe(at)
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	
-	now := time.Now()
-	point := PricePoint{Price: price, Timestamp: now}
-	
-	// Add to price history
-	history := m.priceHistory[marketID]
-	history = append(history, point)
-	
-	// Keep only last 60 minutes
-	cutoff := now.Add(-60 * time.Minute)
-	validIdx := 0
-	for i, p := range history {
-		if p.Timestamp.After(cutoff) {
-			validIdx = i
-			break
-		}
-	}
-	if validIdx > 0 {
-		history = history[validIdx:]
-	}
-	
-	m.priceHistory[marketID] = history
+	m.priceHistory[marketID] = insertPricePoint(m.priceHistory[marketID],
+		PricePoint{Price: price, Timestamp: at}, m.historyCutoff())
 }
 
-// UpdateMarketActivity updates activity stats for a market.
-func (m *MetricsTracker) UpdateMarketActivity(marketID, question string, price, volume float64) {
+// UpdateMarketActivity updates activity stats for a market with a trade at
+// the given event time. LastPrice follows the latest trade by event time.
+func (m *MetricsTracker) UpdateMarketActivity(marketID, question string, price, volume float64, at time.Time) {
+	at = clock.EventTime(at, m.clock)
+	m.events.Observe(at)
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	
@@ -158,29 +204,82 @@ func (m *MetricsTracker) UpdateMarketActivity(marketID, question string, price,
 		}
 		m.marketActivity[marketID] = activity
 	}
+	if activity.Question == "" {
+		activity.Question = question
+	}
 	
 	activity.TradeCount++
 	activity.Volume += volume
-	activity.LastPrice = price
-	activity.LastUpdate = time.Now()
-	
-	// Add price point
-	activity.PricePoints = append(activity.PricePoints, PricePoint{
-		Price:     price,
-		Timestamp: time.Now(),
-	})
+	if !at.Before(activity.LastUpdate) {
+		activity.LastPrice = price
+		activity.LastUpdate = at
+	}
 	
-	// Keep only last 60 minutes
-	cutoff := time.Now().Add(-60 * time.Minute)
-	validIdx := 0
-	for i, p := range activity.PricePoints {
-		if p.Timestamp.After(cutoff) {
-			validIdx = i
-			break
+	activity.PricePoints = insertPricePoint(activity.PricePoints,
+		PricePoint{Price: price, Timestamp: at, ValueUSD: volume}, m.historyCutoff())
+}
+
+// RecentFlow returns the USD traded in a market and its trade count over the
+// price history window, and the share of those trades worth less than value.
+// Call it before recording the trade being valued.
+func (m *MetricsTracker) RecentFlow(marketID string, value float64) (volume float64, trades int, rank float64) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	activity, exists := m.marketActivity[marketID]
+	if !exists || len(activity.PricePoints) == 0 {
+		return 0, 0, 0
+	}
+
+	below := 0
+	for _, p := range activity.PricePoints {
+		volume += p.ValueUSD
+		if p.ValueUSD < value {
+			below++
 		}
 	}
-	if validIdx > 0 {
-		activity.PricePoints = activity.PricePoints[validIdx:]
+	trades = len(activity.PricePoints)
+	return volume, trades, float64(below) / float64(trades)
+}
+
+// historyCutoff returns the event time before which price points are dropped.
+func (m *MetricsTracker) historyCutoff() time.Time {
+	return m.events.Latest().Add(-PriceHistoryWindow)
+}
+
+// insertPricePoint inserts p in event-time order and drops points before cutoff.
+func insertPricePoint(points []PricePoint, p PricePoint, cutoff time.Time) []PricePoint {
+	i := sort.Search(len(points), func(i int) bool { return points[i].Timestamp.After(p.Timestamp) })
+	points = append(points, PricePoint{})
+	copy(points[i+1:], points[i:])
+	points[i] = p
+
+	first := sort.Search(len(points), func(i int) bool { return points[i].Timestamp.After(cutoff) })
+	return points[first:]
+}
+
+// ObserveMarketEvent records a market joining or leaving the subscribed set.
+func (m *MetricsTracker) ObserveMarketEvent(event store.MarketEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch event.Type {
+	case store.MarketAdded:
+		m.marketsAdded++
+		m.activeMarkets[event.MarketID] = true
+		if _, exists := m.marketActivity[event.MarketID]; !exists {
+			m.marketActivity[event.MarketID] = &MarketActivity{
+				MarketID:    event.MarketID,
+				Question:    event.Question,
+				PricePoints: make([]PricePoint, 0, 100),
+				LastUpdate:  event.Timestamp,
+			}
+		}
+	case store.MarketClosed:
+		m.marketsClosed++
+		delete(m.activeMarkets, event.MarketID)
+		delete(m.marketActivity, event.MarketID)
+		delete(m.priceHistory, event.MarketID)
 	}
 }
 
@@ -191,6 +290,112 @@ func (m *MetricsTracker) SetWebSocketStatus(status string) {
 	m.wsStatus = status
 }
 
+// IncrementReconnects increments the WebSocket reconnect counter.
+func (m *MetricsTracker) IncrementReconnects() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.wsReconnects++
+}
+
+// SetShardStatus sets the connection status of a WebSocket shard and
+// recomputes the overall status: connected, degraded or disconnected.
+func (m *MetricsTracker) SetShardStatus(shard, status string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	h := m.shardLocked(shard)
+	h.Status = status
+	h.LastChange = m.clock.Now()
+	m.updateWSStatusLocked()
+}
+
+// IncrementShardReconnects increments the reconnect counter of a shard and the total.
+func (m *MetricsTracker) IncrementShardReconnects(shard string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.shardLocked(shard).Reconnects++
+	m.wsReconnects++
+}
+
+// SetShardAssets sets the number of assets subscribed on a shard.
+func (m *MetricsTracker) SetShardAssets(shard string, assets int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.shardLocked(shard).Assets = assets
+}
+
+// RemoveShard forgets a shard that was closed by the pool.
+func (m *MetricsTracker) RemoveShard(shard string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.wsShards, shard)
+	m.updateWSStatusLocked()
+}
+
+// shardLocked returns the health entry for shard, creating it if needed.
+// Must be called with lock held.
+func (m *MetricsTracker) shardLocked(shard string) *ShardHealth {
+	h, ok := m.wsShards[shard]
+	if !ok {
+		h = &ShardHealth{Status: "disconnected"}
+		m.wsShards[shard] = h
+	}
+	return h
+}
+
+// updateWSStatusLocked derives the overall WebSocket status from the shards.
+// Must be called with lock held.
+func (m *MetricsTracker) updateWSStatusLocked() {
+	connected := 0
+	for _, h := range m.wsShards {
+		if h.Status == "connected" {
+			connected++
+		}
+	}
+
+	switch {
+	case connected == 0:
+		m.wsStatus = "disconnected"
+	case connected < len(m.wsShards):
+		m.wsStatus = "degraded"
+	default:
+		m.wsStatus = "connected"
+	}
+}
+
+// SetDroppedTrades sets the total number of trades dropped for a bus subscriber.
+func (m *MetricsTracker) SetDroppedTrades(subscriber string, dropped uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.droppedTrades[subscriber] = dropped
+}
+
+// IncrementDuplicates counts a trade from source dropped as a cross-source duplicate.
+func (m *MetricsTracker) IncrementDuplicates(source string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.duplicateTrades[source]++
+}
+
+// ObserveRPC records the latency and outcome of an RPC call.
+func (m *MetricsTracker) ObserveRPC(latency time.Duration, success bool) {
+	status := "success"
+	if !success {
+		status = "error"
+	}
+
+	m.mu.Lock()
+	m.rpcCalls[status]++
+	m.mu.Unlock()
+
+	m.rpcLatency.Observe(latency)
+}
+
+// ObserveDetection records how long a trade took to enrich and run through detection.
+func (m *MetricsTracker) ObserveDetection(latency time.Duration) {
+	m.detectionLatency.Observe(latency)
+}
+
 // SetRESTLastPoll sets the last REST API poll time.
 func (m *MetricsTracker) SetRESTLastPoll(t time.Time) {
 	m.mu.Lock()
@@ -206,6 +411,13 @@ func (m *MetricsTracker) SetChannelBuffer(used, capacity int) {
 	m.channelBufferCap = capacity
 }
 
+// SetWorkerQueue sets the buffer usage of one worker shard.
+func (m *MetricsTracker) SetWorkerQueue(worker string, used, capacity int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.workerQueues[worker] = QueueDepth{Used: used, Capacity: capacity}
+}
+
 // Snapshot returns a point-in-time snapshot of metrics.
 func (m *MetricsTracker) Snapshot() MetricsSnapshot {
 	m.mu.RLock()
@@ -215,7 +427,7 @@ func (m *MetricsTracker) Snapshot() MetricsSnapshot {
 	tradeRate := 0.0
 	if len(m.tradeTimestamps) > 0 {
 		oldestTime := m.tradeTimestamps[0]
-		duration := time.Since(oldestTime).Seconds()
+		duration := m.clock.Now().Sub(oldestTime).Seconds()
 		if duration > 0 {
 			tradeRate = float64(len(m.tradeTimestamps)) / duration
 		}
@@ -236,6 +448,31 @@ func (m *MetricsTracker) Snapshot() MetricsSnapshot {
 	
 	// Calculate top movers
 	topMovers := m.calculateTopMovers()
+
+	shardsCopy := make(map[string]ShardHealth, len(m.wsShards))
+	for k, v := range m.wsShards {
+		shardsCopy[k] = *v
+	}
+
+	droppedCopy := make(map[string]uint64, len(m.droppedTrades))
+	for k, v := range m.droppedTrades {
+		droppedCopy[k] = v
+	}
+
+	duplicateCopy := make(map[string]int64, len(m.duplicateTrades))
+	for k, v := range m.duplicateTrades {
+		duplicateCopy[k] = v
+	}
+
+	queuesCopy := make(map[string]QueueDepth, len(m.workerQueues))
+	for k, v := range m.workerQueues {
+		queuesCopy[k] = v
+	}
+
+	rpcCopy := make(map[string]int64, len(m.rpcCalls))
+	for k, v := range m.rpcCalls {
+		rpcCopy[k] = v
+	}
 	
 	return MetricsSnapshot{
 		TradesTotal:       m.tradesTotal,
@@ -244,11 +481,22 @@ func (m *MetricsTracker) Snapshot() MetricsSnapshot {
 		TradeRate:         tradeRate,
 		MarketActivities:  activitiesCopy,
 		TopMovers:         topMovers,
-		Uptime:            time.Since(m.startTime),
+		Uptime:            m.clock.Now().Sub(m.startTime),
 		WebSocketStatus:   m.wsStatus,
 		RESTAPILastPoll:   m.restLastPoll,
 		ChannelBufferUsed: m.channelBufferUsed,
 		ChannelBufferCap:  m.channelBufferCap,
+		WSReconnects:      m.wsReconnects,
+		WSShards:          shardsCopy,
+		DroppedTrades:     droppedCopy,
+		DuplicateTrades:   duplicateCopy,
+		WorkerQueues:      queuesCopy,
+		RPCCalls:          rpcCopy,
+		RPCLatency:        m.rpcLatency.Snapshot(),
+		DetectionLatency:  m.detectionLatency.Snapshot(),
+		MarketsAdded:      m.marketsAdded,
+		MarketsClosed:     m.marketsClosed,
+		ActiveMarkets:     len(m.activeMarkets),
 	}
 }
 
@@ -294,8 +542,12 @@ func (m *MetricsTracker) Cleanup() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	
-	now := time.Now()
-	cutoff := now.Add(-60 * time.Minute)
+	// Activity is aged by event time, so a replay does not wipe old markets
+	now := m.events.Latest()
+	if now.IsZero() {
+		now = m.clock.Now()
+	}
+	cutoff := now.Add(-PriceHistoryWindow)
 	
 	// Clean up market activities with no recent updates
 	for id, activity := range m.marketActivity {
@@ -0,0 +1,176 @@
+// Package orderbook maintains local L2 order books built from market channel events.
+package orderbook
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Side identifies one side of the book.
+type Side int
+
+const (
+	Bid Side = iota
+	Ask
+)
+
+// String returns the side name.
+func (s Side) String() string {
+	if s == Ask {
+		return "ASK"
+	}
+	return "BID"
+}
+
+// ParseSide maps event side names (BUY/BID, SELL/ASK) to a Side.
+func ParseSide(s string) (Side, bool) {
+	switch strings.ToUpper(s) {
+	case "BUY", "BID", "BIDS":
+		return Bid, true
+	case "SELL", "ASK", "ASKS":
+		return Ask, true
+	}
+	return Bid, false
+}
+
+// Level is an aggregated price level.
+type Level struct {
+	Price float64
+	Size  float64 // shares
+}
+
+// book is the L2 book for one asset, owned by a Manager.
+type book struct {
+	market    string
+	assetID   string
+	updatedAt time.Time // event time of the last applied update
+	verified  bool      // last snapshot or price_change hash matched
+	stale     bool      // a price_change hash mismatched since the last snapshot
+	bids      map[float64]float64
+	asks      map[float64]float64
+	wire      [2]map[float64]RawLevel // levels as last sent, by Side, for hashing
+}
+
+// newBook creates an empty book.
+func newBook(market, assetID string) *book {
+	return &book{
+		market:  market,
+		assetID: assetID,
+		bids:    make(map[float64]float64),
+		asks:    make(map[float64]float64),
+		wire:    [2]map[float64]RawLevel{make(map[float64]RawLevel), make(map[float64]RawLevel)},
+	}
+}
+
+// levels returns the price->size map for side.
+func (b *book) levels(side Side) map[float64]float64 {
+	if side == Ask {
+		return b.asks
+	}
+	return b.bids
+}
+
+// set sets the size at price, removing the level when size is zero.
+// raw is the level in wire form.
+func (b *book) set(side Side, price, size float64, raw RawLevel) {
+	levels := b.levels(side)
+	if size <= 0 {
+		delete(levels, price)
+		delete(b.wire[side], price)
+		return
+	}
+	levels[price] = size
+	b.wire[side][price] = raw
+}
+
+// best returns the best level on side: highest bid or lowest ask.
+func (b *book) best(side Side) (Level, bool) {
+	var best Level
+	found := false
+	for price, size := range b.levels(side) {
+		if !found || better(side, price, best.Price) {
+			best = Level{Price: price, Size: size}
+			found = true
+		}
+	}
+	return best, found
+}
+
+// depthAt sums the size of every level at price or better.
+func (b *book) depthAt(side Side, price float64) float64 {
+	total := 0.0
+	for p, size := range b.levels(side) {
+		if p == price || better(side, p, price) {
+			total += size
+		}
+	}
+	return total
+}
+
+// sorted returns the levels on side from best to worst.
+func (b *book) sorted(side Side) []Level {
+	levels := make([]Level, 0, len(b.levels(side)))
+	for price, size := range b.levels(side) {
+		levels = append(levels, Level{Price: price, Size: size})
+	}
+	sort.Slice(levels, func(i, j int) bool {
+		return better(side, levels[i].Price, levels[j].Price)
+	})
+	return levels
+}
+
+// raw returns the levels on side from best to worst in wire form.
+func (b *book) raw(side Side) []RawLevel {
+	levels := b.sorted(side)
+	raw := make([]RawLevel, len(levels))
+	for i, lvl := range levels {
+		raw[i] = b.wire[side][lvl.Price]
+	}
+	return raw
+}
+
+// better reports whether price a is better than b on side.
+func better(side Side, a, b float64) bool {
+	if side == Ask {
+		return a < b
+	}
+	return a > b
+}
+
+// View is a point-in-time copy of a book.
+type View struct {
+	Market    string
+	AssetID   string
+	UpdatedAt time.Time
+	Verified  bool
+	Stale     bool    // a price_change hash mismatched; cleared by the next snapshot
+	Bids      []Level // best first
+	Asks      []Level // best first
+}
+
+// BestBid returns the highest bid.
+func (v View) BestBid() (Level, bool) {
+	if len(v.Bids) == 0 {
+		return Level{}, false
+	}
+	return v.Bids[0], true
+}
+
+// BestAsk returns the lowest ask.
+func (v View) BestAsk() (Level, bool) {
+	if len(v.Asks) == 0 {
+		return Level{}, false
+	}
+	return v.Asks[0], true
+}
+
+// Spread returns best ask minus best bid.
+func (v View) Spread() (float64, bool) {
+	bid, okBid := v.BestBid()
+	ask, okAsk := v.BestAsk()
+	if !okBid || !okAsk {
+		return 0, false
+	}
+	return ask.Price - bid.Price, true
+}
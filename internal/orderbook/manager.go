@@ -0,0 +1,318 @@
+package orderbook
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RawLevel is a price level as sent on the wire.
+type RawLevel struct {
+	Price string `json:"price"`
+	Size  string `json:"size"`
+}
+
+// Snapshot is a full book for one asset (event_type "book").
+type Snapshot struct {
+	Market    string
+	AssetID   string
+	Timestamp string // unix ms
+	Hash      string
+	Bids      []RawLevel
+	Asks      []RawLevel
+}
+
+// PriceChange updates one price level (event_type "price_change").
+// Size is the new aggregate size at the level; Delta, when set instead,
+// is added to the current size.
+type PriceChange struct {
+	Market    string
+	AssetID   string
+	Timestamp string // unix ms
+	Side      string // BUY/BID or SELL/ASK
+	Price     string
+	Size      string
+	Delta     string
+	Hash      string // hash of the book after the change, if sent
+}
+
+// Stats counts processed book events.
+type Stats struct {
+	Books        int
+	Snapshots    uint64
+	Changes      uint64
+	Orphans      uint64 // changes for assets with no snapshot yet
+	Stale        uint64 // changes older than the current book
+	StaleBooks   int    // books whose price_change hash mismatched since their snapshot
+	HashVerified uint64 // snapshot and price_change hashes that matched
+	HashMismatch uint64
+}
+
+// Manager holds the books for every subscribed asset.
+type Manager struct {
+	mu    sync.RWMutex
+	books map[string]*book // asset ID -> book
+	stats Stats
+}
+
+// NewManager creates an empty Manager.
+func NewManager() *Manager {
+	return &Manager{
+		books: make(map[string]*book),
+	}
+}
+
+// ApplySnapshot replaces the book for the snapshot's asset.
+// The hash is checked best-effort; a mismatch is counted but the
+// snapshot is still applied since it is the authoritative state.
+func (m *Manager) ApplySnapshot(s Snapshot) error {
+	if s.AssetID == "" {
+		return fmt.Errorf("snapshot missing asset_id")
+	}
+
+	b := newBook(s.Market, s.AssetID)
+	b.updatedAt = parseMillis(s.Timestamp)
+	for _, lvl := range s.Bids {
+		if err := setRaw(b, Bid, lvl.Price, lvl.Size); err != nil {
+			return err
+		}
+	}
+	for _, lvl := range s.Asks {
+		if err := setRaw(b, Ask, lvl.Price, lvl.Size); err != nil {
+			return err
+		}
+	}
+
+	verified := s.Hash != "" && SnapshotHash(s) == s.Hash
+	b.verified = verified
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.books[s.AssetID] = b
+	m.stats.Snapshots++
+	if s.Hash != "" {
+		if verified {
+			m.stats.HashVerified++
+		} else {
+			m.stats.HashMismatch++
+		}
+	}
+	return nil
+}
+
+// ApplyChange applies a single level update. Changes for assets without a
+// snapshot, or older than the book, are ignored. When the change carries a
+// hash, the book is checked against it and marked stale on a mismatch until
+// the next snapshot; like snapshot hashes this is best-effort.
+func (m *Manager) ApplyChange(c PriceChange) error {
+	side, ok := ParseSide(c.Side)
+	if !ok {
+		return fmt.Errorf("unknown side %q", c.Side)
+	}
+	price, err := strconv.ParseFloat(c.Price, 64)
+	if err != nil {
+		return fmt.Errorf("invalid price %q: %w", c.Price, err)
+	}
+	ts := parseMillis(c.Timestamp)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	b, exists := m.books[c.AssetID]
+	if !exists {
+		m.stats.Orphans++
+		return nil
+	}
+	if !ts.IsZero() && ts.Before(b.updatedAt) {
+		m.stats.Stale++
+		return nil
+	}
+
+	size, sizeStr := b.levels(side)[price], c.Size
+	switch {
+	case c.Size != "":
+		if size, err = strconv.ParseFloat(c.Size, 64); err != nil {
+			return fmt.Errorf("invalid size %q: %w", c.Size, err)
+		}
+	case c.Delta != "":
+		delta, err := strconv.ParseFloat(c.Delta, 64)
+		if err != nil {
+			return fmt.Errorf("invalid delta %q: %w", c.Delta, err)
+		}
+		size += delta
+		sizeStr = strconv.FormatFloat(size, 'f', -1, 64)
+	default:
+		return fmt.Errorf("price change missing size")
+	}
+
+	b.set(side, price, size, RawLevel{Price: c.Price, Size: sizeStr})
+	if !ts.IsZero() {
+		b.updatedAt = ts
+	}
+	m.stats.Changes++
+
+	if c.Hash != "" {
+		market := c.Market
+		if market == "" {
+			market = b.market
+		}
+		b.verified = SnapshotHash(Snapshot{
+			Market:    market,
+			AssetID:   c.AssetID,
+			Timestamp: c.Timestamp,
+			Bids:      b.raw(Bid),
+			Asks:      b.raw(Ask),
+		}) == c.Hash
+		if b.verified {
+			m.stats.HashVerified++
+		} else {
+			m.stats.HashMismatch++
+			b.stale = true
+		}
+	}
+	return nil
+}
+
+// Remove drops the book for assetID (e.g. when its market closes).
+func (m *Manager) Remove(assetID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.books, assetID)
+}
+
+// BestBid returns the highest bid for assetID.
+func (m *Manager) BestBid(assetID string) (Level, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if b, ok := m.books[assetID]; ok {
+		return b.best(Bid)
+	}
+	return Level{}, false
+}
+
+// BestAsk returns the lowest ask for assetID.
+func (m *Manager) BestAsk(assetID string) (Level, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if b, ok := m.books[assetID]; ok {
+		return b.best(Ask)
+	}
+	return Level{}, false
+}
+
+// Spread returns best ask minus best bid for assetID.
+func (m *Manager) Spread(assetID string) (float64, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	b, ok := m.books[assetID]
+	if !ok {
+		return 0, false
+	}
+	bid, okBid := b.best(Bid)
+	ask, okAsk := b.best(Ask)
+	if !okBid || !okAsk {
+		return 0, false
+	}
+	return ask.Price - bid.Price, true
+}
+
+// DepthAt returns the total size resting on side at price or better,
+// i.e. what could be filled against that side without going past price.
+func (m *Manager) DepthAt(assetID string, side Side, price float64) (float64, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	b, ok := m.books[assetID]
+	if !ok {
+		return 0, false
+	}
+	return b.depthAt(side, price), true
+}
+
+// Book returns a copy of the book for assetID.
+func (m *Manager) Book(assetID string) (View, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	b, ok := m.books[assetID]
+	if !ok {
+		return View{}, false
+	}
+	return View{
+		Market:    b.market,
+		AssetID:   b.assetID,
+		UpdatedAt: b.updatedAt,
+		Verified:  b.verified,
+		Stale:     b.stale,
+		Bids:      b.sorted(Bid),
+		Asks:      b.sorted(Ask),
+	}, true
+}
+
+// Stats returns event counters.
+func (m *Manager) Stats() Stats {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stats := m.stats
+	stats.Books = len(m.books)
+	for _, b := range m.books {
+		if b.stale {
+			stats.StaleBooks++
+		}
+	}
+	return stats
+}
+
+// SnapshotHash computes the book hash the way the CLOB client does: SHA-1
+// of the compact JSON summary with an empty hash field. The exchange does
+// not document the exact field set, so treat a mismatch as "unverified"
+// rather than as corruption.
+func SnapshotHash(s Snapshot) string {
+	summary := struct {
+		Market    string     `json:"market"`
+		AssetID   string     `json:"asset_id"`
+		Timestamp string     `json:"timestamp"`
+		Hash      string     `json:"hash"`
+		Bids      []RawLevel `json:"bids"`
+		Asks      []RawLevel `json:"asks"`
+	}{s.Market, s.AssetID, s.Timestamp, "", s.Bids, s.Asks}
+
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return ""
+	}
+	sum := sha1.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// setRaw parses a wire level and sets it on the book.
+func setRaw(b *book, side Side, priceStr, sizeStr string) error {
+	price, err := strconv.ParseFloat(priceStr, 64)
+	if err != nil {
+		return fmt.Errorf("invalid price %q: %w", priceStr, err)
+	}
+	size, err := strconv.ParseFloat(sizeStr, 64)
+	if err != nil {
+		return fmt.Errorf("invalid size %q: %w", sizeStr, err)
+	}
+	b.set(side, price, size, RawLevel{Price: priceStr, Size: sizeStr})
+	return nil
+}
+
+// parseMillis parses a unix millisecond timestamp, returning zero on failure.
+func parseMillis(s string) time.Time {
+	ms, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || ms <= 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
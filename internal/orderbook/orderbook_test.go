@@ -0,0 +1,128 @@
+package orderbook
+
+import (
+	"math"
+	"testing"
+)
+
+func testSnapshot() Snapshot {
+	return Snapshot{
+		Market:    "0xmarket",
+		AssetID:   "asset",
+		Timestamp: "1767527823560",
+		Bids:      []RawLevel{{"0.55", "1000"}, {"0.54", "500"}},
+		Asks:      []RawLevel{{"0.60", "800"}, {"0.61", "1200"}},
+	}
+}
+
+func TestBookQueries(t *testing.T) {
+	m := NewManager()
+	if err := m.ApplySnapshot(testSnapshot()); err != nil {
+		t.Fatalf("ApplySnapshot failed: %v", err)
+	}
+
+	bid, _ := m.BestBid("asset")
+	ask, _ := m.BestAsk("asset")
+	if bid.Price != 0.55 || ask.Price != 0.60 {
+		t.Errorf("Expected best 0.55/0.60, got %v/%v", bid.Price, ask.Price)
+	}
+	if spread, _ := m.Spread("asset"); math.Abs(spread-0.05) > 1e-9 {
+		t.Errorf("Expected spread 0.05, got %v", spread)
+	}
+	if depth, _ := m.DepthAt("asset", Ask, 0.61); depth != 2000 {
+		t.Errorf("Expected ask depth 2000 up to 0.61, got %v", depth)
+	}
+	if depth, _ := m.DepthAt("asset", Bid, 0.55); depth != 1000 {
+		t.Errorf("Expected bid depth 1000 at 0.55, got %v", depth)
+	}
+	if _, ok := m.BestBid("unknown"); ok {
+		t.Error("Expected no book for unknown asset")
+	}
+}
+
+func TestPriceChanges(t *testing.T) {
+	m := NewManager()
+	m.ApplySnapshot(testSnapshot())
+
+	changes := []PriceChange{
+		{AssetID: "asset", Timestamp: "1767527823600", Side: "BUY", Price: "0.56", Size: "300"}, // new best bid
+		{AssetID: "asset", Timestamp: "1767527823600", Side: "SELL", Price: "0.60", Size: "0"},  // level removed
+		{AssetID: "asset", Timestamp: "1767527823600", Side: "BID", Price: "0.54", Delta: "-200"},
+		{AssetID: "asset", Timestamp: "1767527823000", Side: "BUY", Price: "0.90", Size: "1"}, // stale
+		{AssetID: "other", Timestamp: "1767527823600", Side: "BUY", Price: "0.50", Size: "1"}, // orphan
+	}
+	for _, c := range changes {
+		if err := m.ApplyChange(c); err != nil {
+			t.Fatalf("ApplyChange failed: %v", err)
+		}
+	}
+
+	view, _ := m.Book("asset")
+	if bid, _ := view.BestBid(); bid.Price != 0.56 {
+		t.Errorf("Expected best bid 0.56, got %v", bid.Price)
+	}
+	if ask, _ := view.BestAsk(); ask.Price != 0.61 {
+		t.Errorf("Expected best ask 0.61 after removal, got %v", ask.Price)
+	}
+	if view.Bids[2].Size != 300 {
+		t.Errorf("Expected 0.54 level reduced to 300, got %v", view.Bids[2].Size)
+	}
+
+	st := m.Stats()
+	if st.Changes != 3 || st.Stale != 1 || st.Orphans != 1 {
+		t.Errorf("Unexpected stats %+v", st)
+	}
+}
+
+func TestSnapshotHashVerification(t *testing.T) {
+	m := NewManager()
+
+	good := testSnapshot()
+	good.Hash = SnapshotHash(good)
+	m.ApplySnapshot(good)
+
+	bad := testSnapshot()
+	bad.Hash = "deadbeef"
+	m.ApplySnapshot(bad)
+
+	st := m.Stats()
+	if st.HashVerified != 1 || st.HashMismatch != 1 {
+		t.Errorf("Expected 1 verified and 1 mismatch, got %+v", st)
+	}
+	if view, _ := m.Book("asset"); view.Verified {
+		t.Error("Expected latest book to be unverified")
+	}
+}
+
+func TestPriceChangeHashVerification(t *testing.T) {
+	m := NewManager()
+	m.ApplySnapshot(testSnapshot())
+
+	// Hash of the test book after a new 0.56 bid of 300, at the change's timestamp
+	const hash = "1b4498d6cf00bd60532f8b57847fc6ac01a92e8c"
+	good := PriceChange{AssetID: "asset", Timestamp: "1767527823600", Side: "BUY", Price: "0.56", Size: "300", Hash: hash}
+	if err := m.ApplyChange(good); err != nil {
+		t.Fatalf("ApplyChange failed: %v", err)
+	}
+	if view, _ := m.Book("asset"); !view.Verified || view.Stale {
+		t.Errorf("Expected a verified book after a matching hash, got %+v", view)
+	}
+
+	bad := PriceChange{AssetID: "asset", Timestamp: "1767527823700", Side: "SELL", Price: "0.60", Size: "0", Hash: "deadbeef"}
+	if err := m.ApplyChange(bad); err != nil {
+		t.Fatalf("ApplyChange failed: %v", err)
+	}
+	if view, _ := m.Book("asset"); view.Verified || !view.Stale {
+		t.Errorf("Expected a stale book after a mismatched hash, got %+v", view)
+	}
+	st := m.Stats()
+	if st.HashVerified != 1 || st.HashMismatch != 1 || st.StaleBooks != 1 {
+		t.Errorf("Expected 1 verified, 1 mismatch and 1 stale book, got %+v", st)
+	}
+
+	// Only the next snapshot clears it
+	m.ApplySnapshot(testSnapshot())
+	if view, _ := m.Book("asset"); view.Stale {
+		t.Error("Expected the snapshot to clear the stale flag")
+	}
+}
@@ -8,9 +8,15 @@ type Trade struct {
 	// ID is a unique identifier for this trade record
 	ID string
 
-	// MarketID is the market/condition token ID
+	// MarketID is the market condition ID
 	MarketID string
 
+	// Question is the market question (from the asset registry)
+	Question string
+
+	// MarketSlug is the Gamma market slug (from the asset registry)
+	MarketSlug string
+
 	// AssetID is the specific outcome token ID
 	AssetID string
 
@@ -32,9 +38,12 @@ type Trade struct {
 	// Price is the execution price (0-1 range for prediction markets)
 	Price float64
 
-	// ValueUSD is the calculated USD value of the trade
+	// ValueUSD is the USDC notional of the fill (shares x price of the traded token)
 	ValueUSD float64
 
+	// ValuationMethod records how ValueUSD was derived, e.g. "shares_x_price:buy"
+	ValuationMethod string
+
 	// Timestamp is when the trade occurred
 	Timestamp time.Time
 
@@ -43,22 +52,75 @@ type Trade struct {
 
 	// TransactionHash is the on-chain transaction hash (if available)
 	TransactionHash string
+
+	// Source is the feed the trade arrived on (websocket or rest)
+	Source string
 }
 
+// Trade sources
+const (
+	SourceWebSocket = "websocket"
+	SourceREST      = "rest"
+)
+
 // Signal types for detection
 const (
-	SignalFreshInsider = "FRESH_INSIDER"
-	SignalWhale        = "WHALE"
-	SignalPanicBurst   = "PANIC_BURST"
-	SignalPriceShock   = "PRICE_SHOCK" // New signal for rapid price moves > 5%
+	SignalFreshInsider  = "FRESH_INSIDER"
+	SignalWhale         = "WHALE"
+	SignalPanicBurst    = "PANIC_BURST"
+	SignalPriceShock    = "PRICE_SHOCK"    // Rapid price move within a window
+	SignalRelativeWhale = "RELATIVE_WHALE" // Trade large relative to its market
+	SignalVolumeAnomaly = "VOLUME_ANOMALY" // Market volume far above its baseline
+	SignalAccumulation  = "ACCUMULATION"   // Split orders adding up to a whale position
+	SignalWashTrade     = "WASH_TRADE"     // Self-matched or ping-pong trades
+	SignalFreshSwarm    = "FRESH_SWARM"    // Many fresh wallets on the same side of an asset
+)
+
+// Signal severities, from least to most urgent
+const (
+	SeverityLow      = "low"
+	SeverityMedium   = "medium"
+	SeverityHigh     = "high"
+	SeverityCritical = "critical"
 )
 
 // Suspect represents a trade that triggered a detection signal.
 type Suspect struct {
 	Trade      Trade
 	SignalType string
+	Severity   string // one of the Severity* constants
 	Nonce      int // Wallet transaction count (for FRESH_INSIDER)
 	Meta       map[string]interface{} // Extra context (e.g., price delta)
+
+	Funder      string // address that first funded the maker, "" if not traced
+	ClusterSize int    // wallets known to share that funder, including the maker
+}
+
+// Market event types emitted by market discovery
+const (
+	MarketAdded  = "added"
+	MarketClosed = "closed"
+)
+
+// MarketEvent reports a market entering or leaving the subscribed set.
+type MarketEvent struct {
+	Type      string // MarketAdded or MarketClosed
+	MarketID  string // condition ID, matching Trade.MarketID
+	Question  string
+	Slug      string
+	TokenIDs  []string
+	Timestamp time.Time
+}
+
+// Funding is the first inbound MATIC or USDC transfer to a wallet. Wallets
+// with the same funder form a cluster.
+type Funding struct {
+	Wallet   string
+	Funder   string
+	Asset    string // e.g. MATIC, POL or USDC
+	TxHash   string
+	Block    uint64
+	FundedAt time.Time // block time, zero if unknown
 }
 
 // Alert represents a notification to be sent.
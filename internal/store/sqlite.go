@@ -0,0 +1,354 @@
+package store
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3" // SQLite driver
+)
+
+// schema creates the trades and alerts tables (spec Section 5.1).
+// The signal_type CHECK constraint from the spec is omitted so new signal
+// types do not require a migration.
+const schema = `
+CREATE TABLE IF NOT EXISTS trades (
+    id TEXT PRIMARY KEY,
+    trade_id TEXT NOT NULL,
+    market_id TEXT NOT NULL,
+    market_name TEXT,
+    asset_id TEXT NOT NULL,
+    maker_address TEXT NOT NULL,
+    taker_address TEXT,
+    side TEXT NOT NULL,
+    outcome TEXT,
+    size_raw TEXT NOT NULL,
+    value_usd REAL NOT NULL,
+    valuation_method TEXT,
+    price REAL,
+    nonce INTEGER,
+    signal_type TEXT NOT NULL,
+    meta TEXT,
+    traded_at TEXT,
+    funder TEXT,
+    cluster_size INTEGER,
+    created_at TEXT DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_trades_maker ON trades(maker_address);
+CREATE INDEX IF NOT EXISTS idx_trades_signal ON trades(signal_type);
+CREATE INDEX IF NOT EXISTS idx_trades_created ON trades(created_at);
+CREATE INDEX IF NOT EXISTS idx_trades_market ON trades(market_id);
+
+CREATE TABLE IF NOT EXISTS alerts (
+    id TEXT PRIMARY KEY,
+    trade_ids TEXT NOT NULL,
+    wallet_address TEXT NOT NULL,
+    signal_type TEXT NOT NULL,
+    summary TEXT NOT NULL,
+    sent_at TEXT DEFAULT (datetime('now')),
+    discord_success INTEGER DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_alerts_wallet ON alerts(wallet_address);
+CREATE INDEX IF NOT EXISTS idx_alerts_sent ON alerts(sent_at);
+
+CREATE TABLE IF NOT EXISTS wallet_funding (
+    wallet TEXT PRIMARY KEY,
+    funder TEXT NOT NULL,
+    asset TEXT,
+    tx_hash TEXT,
+    block INTEGER,
+    funded_at TEXT,
+    created_at TEXT DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_funding_funder ON wallet_funding(funder);
+`
+
+// columnMigrations adds columns introduced after a database was first created.
+var columnMigrations = []struct {
+	table, column, definition string
+}{
+	{"trades", "valuation_method", "TEXT"},
+	{"trades", "funder", "TEXT"},
+	{"trades", "cluster_size", "INTEGER"},
+}
+
+// DB wraps the SQLite database used for suspect and alert history.
+type DB struct {
+	db *sql.DB
+}
+
+// Open opens (creating if needed) the SQLite database at path and applies the schema.
+func Open(path string) (*DB, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("create db directory failed: %w", err)
+		}
+	}
+
+	db, err := sql.Open("sqlite3", path+"?_journal_mode=WAL&_busy_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("open db failed: %w", err)
+	}
+
+	// SQLite allows a single writer; serialize access through one connection
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("apply schema failed: %w", err)
+	}
+
+	if err := migrateColumns(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &DB{db: db}, nil
+}
+
+// migrateColumns adds any missing columns from columnMigrations.
+func migrateColumns(db *sql.DB) error {
+	for _, m := range columnMigrations {
+		exists, err := hasColumn(db, m.table, m.column)
+		if err != nil {
+			return fmt.Errorf("inspect %s failed: %w", m.table, err)
+		}
+		if exists {
+			continue
+		}
+		if _, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", m.table, m.column, m.definition)); err != nil {
+			return fmt.Errorf("add column %s.%s failed: %w", m.table, m.column, err)
+		}
+	}
+	return nil
+}
+
+// hasColumn reports whether table has column.
+func hasColumn(db *sql.DB, table, column string) (bool, error) {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid        int
+			name, kind string
+			notNull    int
+			dflt       sql.NullString
+			pk         int
+		)
+		if err := rows.Scan(&cid, &name, &kind, &notNull, &dflt, &pk); err != nil {
+			return false, err
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
+
+// Close closes the database.
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+// InsertSuspects writes a batch of suspects in a single transaction.
+func (d *DB) InsertSuspects(suspects []Suspect) error {
+	if len(suspects) == 0 {
+		return nil
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin tx failed: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`INSERT INTO trades (
+		id, trade_id, market_id, market_name, asset_id, maker_address, taker_address,
+		side, outcome, size_raw, value_usd, valuation_method, price, nonce, signal_type, meta, traded_at,
+		funder, cluster_size
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("prepare insert failed: %w", err)
+	}
+	defer stmt.Close()
+
+	unfunded := make(map[string]bool)
+	for _, s := range suspects {
+		var nonce interface{}
+		if s.Nonce >= 0 {
+			nonce = s.Nonce
+		}
+
+		var clusterSize interface{}
+		if s.ClusterSize > 0 {
+			clusterSize = s.ClusterSize
+		}
+
+		var meta interface{}
+		if len(s.Meta) > 0 {
+			encoded, err := json.Marshal(s.Meta)
+			if err != nil {
+				return fmt.Errorf("encode meta failed: %w", err)
+			}
+			meta = string(encoded)
+		}
+
+		t := s.Trade
+		if _, err := stmt.Exec(
+			newID(), t.ID, t.MarketID, nullString(t.Question), t.AssetID, t.MakerAddress, nullString(t.TakerAddress),
+			t.Side, nullString(t.Outcome), t.Size, t.ValueUSD, nullString(t.ValuationMethod), t.Price, nonce,
+			s.SignalType, meta, formatTime(t.Timestamp),
+			nullString(s.Funder), clusterSize,
+		); err != nil {
+			return fmt.Errorf("insert suspect failed: %w", err)
+		}
+		if s.Funder == "" {
+			unfunded[strings.ToLower(t.MakerAddress)] = true
+		}
+	}
+
+	// Funding is traced in the background, so it may be recorded before
+	// the suspects it belongs to reach the database
+	for wallet := range unfunded {
+		if _, err := tx.Exec(attachFunding, wallet); err != nil {
+			return fmt.Errorf("attach funding failed: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit failed: %w", err)
+	}
+
+	return nil
+}
+
+// InsertAlert records an alert attempt.
+func (d *DB) InsertAlert(alert Alert) error {
+	if alert.ID == "" {
+		alert.ID = newID()
+	}
+
+	tradeIDs, err := json.Marshal(alert.TradeIDs)
+	if err != nil {
+		return fmt.Errorf("encode trade ids failed: %w", err)
+	}
+
+	success := 0
+	if alert.Success {
+		success = 1
+	}
+
+	_, err = d.db.Exec(`INSERT INTO alerts (
+		id, trade_ids, wallet_address, signal_type, summary, sent_at, discord_success
+	) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		alert.ID, string(tradeIDs), alert.WalletAddress, alert.SignalType, alert.Summary,
+		formatTime(alert.SentAt), success,
+	)
+	if err != nil {
+		return fmt.Errorf("insert alert failed: %w", err)
+	}
+
+	return nil
+}
+
+// attachFunding sets the funder and cluster size of a wallet's suspects
+// stored without one. The wallet must be lower case.
+const attachFunding = `UPDATE trades SET
+    funder = f.funder,
+    cluster_size = (SELECT COUNT(*) FROM wallet_funding c WHERE c.funder = f.funder)
+FROM wallet_funding f
+WHERE f.wallet = ? AND lower(trades.maker_address) = f.wallet AND trades.funder IS NULL`
+
+// SaveFunding records a wallet's funding and attaches it to the wallet's
+// suspects stored without one. The first funding recorded for a wallet is kept.
+func (d *DB) SaveFunding(f Funding) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin tx failed: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`INSERT OR IGNORE INTO wallet_funding (
+		wallet, funder, asset, tx_hash, block, funded_at
+	) VALUES (?, ?, ?, ?, ?, ?)`,
+		f.Wallet, f.Funder, nullString(f.Asset), nullString(f.TxHash), f.Block, formatTime(f.FundedAt),
+	)
+	if err != nil {
+		return fmt.Errorf("insert funding failed: %w", err)
+	}
+	if _, err := tx.Exec(attachFunding, strings.ToLower(f.Wallet)); err != nil {
+		return fmt.Errorf("attach funding failed: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit failed: %w", err)
+	}
+	return nil
+}
+
+// LoadFundings returns every recorded wallet funding.
+func (d *DB) LoadFundings() ([]Funding, error) {
+	rows, err := d.db.Query(`SELECT wallet, funder, asset, tx_hash, block, funded_at FROM wallet_funding`)
+	if err != nil {
+		return nil, fmt.Errorf("query fundings failed: %w", err)
+	}
+	defer rows.Close()
+
+	var fundings []Funding
+	for rows.Next() {
+		var (
+			f                       Funding
+			asset, txHash, fundedAt sql.NullString
+			block                   sql.NullInt64
+		)
+		if err := rows.Scan(&f.Wallet, &f.Funder, &asset, &txHash, &block, &fundedAt); err != nil {
+			return nil, fmt.Errorf("scan funding failed: %w", err)
+		}
+		f.Asset, f.TxHash, f.Block = asset.String, txHash.String, uint64(block.Int64)
+		if fundedAt.Valid {
+			f.FundedAt, _ = time.Parse("2006-01-02 15:04:05", fundedAt.String)
+		}
+		fundings = append(fundings, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read fundings failed: %w", err)
+	}
+	return fundings, nil
+}
+
+// newID generates a random UUID v4 string.
+func newID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// nullString maps empty strings to NULL.
+func nullString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// formatTime formats a time in the same layout as SQLite's datetime('now').
+func formatTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC().Format("2006-01-02 15:04:05")
+}
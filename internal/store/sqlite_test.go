@@ -0,0 +1,203 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriterFlushesOnShutdown(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "trades.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+
+	in := make(chan Suspect, 10)
+	w := NewWriter(db, in)
+	ctx, cancel := context.WithCancel(context.Background())
+	w.Start(ctx)
+
+	in <- Suspect{
+		Trade:      Trade{ID: "t1", AssetID: "a1", Side: "BUY", Size: "10", ValueUSD: 60000, Timestamp: time.Now()},
+		SignalType: SignalWhale,
+		Nonce:      -1,
+	}
+	in <- Suspect{
+		Trade:      Trade{ID: "t1", AssetID: "a1", Side: "BUY", Size: "10", ValueUSD: 60000, Timestamp: time.Now()},
+		SignalType: SignalFreshInsider,
+		Nonce:      2,
+		Meta:       map[string]interface{}{"note": "same trade, second signal"},
+	}
+
+	cancel()
+	w.Wait()
+
+	var count int
+	if err := db.db.QueryRow(`SELECT COUNT(*) FROM trades WHERE trade_id = 't1'`).Scan(&count); err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 rows, got %d", count)
+	}
+
+	var nonce *int
+	if err := db.db.QueryRow(`SELECT nonce FROM trades WHERE signal_type = ?`, SignalWhale).Scan(&nonce); err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	if nonce != nil {
+		t.Errorf("Expected NULL nonce for unenriched suspect, got %d", *nonce)
+	}
+}
+
+func TestInsertAlert(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "trades.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+
+	err = db.InsertAlert(Alert{
+		TradeIDs:      []string{"t1", "t2"},
+		WalletAddress: "0xabc",
+		SignalType:    SignalPanicBurst,
+		Summary:       "burst",
+		SentAt:        time.Now(),
+		Success:       true,
+	})
+	if err != nil {
+		t.Fatalf("InsertAlert failed: %v", err)
+	}
+
+	var tradeIDs string
+	var success int
+	if err := db.db.QueryRow(`SELECT trade_ids, discord_success FROM alerts`).Scan(&tradeIDs, &success); err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	if tradeIDs != `["t1","t2"]` || success != 1 {
+		t.Errorf("Unexpected alert row: trade_ids=%s success=%d", tradeIDs, success)
+	}
+}
+
+func TestOpenAddsMissingColumns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "old.db")
+
+	// Simulate a database created before valuation_method existed
+	old, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	if _, err := old.Exec(`CREATE TABLE trades (
+		id TEXT PRIMARY KEY, trade_id TEXT NOT NULL, market_id TEXT NOT NULL, market_name TEXT,
+		asset_id TEXT NOT NULL, maker_address TEXT NOT NULL, taker_address TEXT, side TEXT NOT NULL,
+		outcome TEXT, size_raw TEXT NOT NULL, value_usd REAL NOT NULL, price REAL, nonce INTEGER,
+		signal_type TEXT NOT NULL, meta TEXT, traded_at TEXT, created_at TEXT
+	)`); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	old.Close()
+
+	db, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+
+	for _, column := range []string{"valuation_method", "funder", "cluster_size"} {
+		if ok, err := hasColumn(db.db, "trades", column); err != nil || !ok {
+			t.Errorf("Expected %s column to be added, got %v, %v", column, ok, err)
+		}
+	}
+}
+
+func TestSchemaIncludesMigratedColumns(t *testing.T) {
+	// New databases must match migrated ones without relying on the migrations
+	for _, m := range columnMigrations {
+		if !strings.Contains(schema, "    "+m.column+" "+m.definition+",") {
+			t.Errorf("CREATE TABLE %s is missing %s %s", m.table, m.column, m.definition)
+		}
+	}
+}
+
+func TestFundingRoundTrip(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "trades.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+
+	fundedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	first := Funding{Wallet: "0xw1", Funder: "0xf", Asset: "USDC", TxHash: "0xtx", Block: 42, FundedAt: fundedAt}
+	if err := db.SaveFunding(first); err != nil {
+		t.Fatalf("SaveFunding failed: %v", err)
+	}
+	// A wallet's first funding is kept
+	if err := db.SaveFunding(Funding{Wallet: "0xw1", Funder: "0xother"}); err != nil {
+		t.Fatalf("SaveFunding failed: %v", err)
+	}
+
+	fundings, err := db.LoadFundings()
+	if err != nil {
+		t.Fatalf("LoadFundings failed: %v", err)
+	}
+	if len(fundings) != 1 {
+		t.Fatalf("Expected 1 funding, got %d", len(fundings))
+	}
+	got := fundings[0]
+	if got.Funder != "0xf" || got.Block != 42 || !got.FundedAt.Equal(fundedAt) {
+		t.Errorf("Unexpected funding: %+v", got)
+	}
+}
+
+func TestFundingAttachedToSuspects(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "trades.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+
+	suspect := func(id, maker string) Suspect {
+		return Suspect{
+			Trade:      Trade{ID: id, AssetID: "a1", MakerAddress: maker, Side: "BUY", Size: "10", ValueUSD: 60000},
+			SignalType: SignalWhale,
+			Nonce:      -1,
+		}
+	}
+	funderOf := func(id string) (sql.NullString, sql.NullInt64) {
+		var funder sql.NullString
+		var size sql.NullInt64
+		if err := db.db.QueryRow(`SELECT funder, cluster_size FROM trades WHERE trade_id = ?`, id).Scan(&funder, &size); err != nil {
+			t.Fatalf("query failed: %v", err)
+		}
+		return funder, size
+	}
+
+	if err := db.SaveFunding(Funding{Wallet: "0xw1", Funder: "0xf"}); err != nil {
+		t.Fatalf("SaveFunding failed: %v", err)
+	}
+
+	// Suspects written before their wallet's trace are patched by it
+	if err := db.InsertSuspects([]Suspect{suspect("t2", "0xW2")}); err != nil {
+		t.Fatalf("InsertSuspects failed: %v", err)
+	}
+	if funder, _ := funderOf("t2"); funder.Valid {
+		t.Fatalf("Expected no funder before the trace, got %q", funder.String)
+	}
+	if err := db.SaveFunding(Funding{Wallet: "0xw2", Funder: "0xf"}); err != nil {
+		t.Fatalf("SaveFunding failed: %v", err)
+	}
+	if funder, size := funderOf("t2"); funder.String != "0xf" || size.Int64 != 2 {
+		t.Errorf("Expected funder 0xf in a cluster of 2, got %q, %d", funder.String, size.Int64)
+	}
+
+	// Suspects written after the trace pick up the recorded funding
+	if err := db.InsertSuspects([]Suspect{suspect("t1", "0xW1")}); err != nil {
+		t.Fatalf("InsertSuspects failed: %v", err)
+	}
+	if funder, size := funderOf("t1"); funder.String != "0xf" || size.Int64 != 2 {
+		t.Errorf("Expected funder 0xf in a cluster of 2, got %q, %d", funder.String, size.Int64)
+	}
+}
@@ -0,0 +1,102 @@
+package store
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Write batching per spec Section 5.2
+const (
+	// BatchSize is the number of records that triggers a flush
+	BatchSize = 100
+	// BatchInterval is the maximum time records wait before a flush
+	BatchInterval = 1 * time.Second
+)
+
+// Writer consumes suspects and writes them to the database in batches.
+type Writer struct {
+	db    *DB
+	in    <-chan Suspect
+	batch []Suspect
+	done  chan struct{}
+}
+
+// NewWriter creates a new Writer reading from in.
+func NewWriter(db *DB, in <-chan Suspect) *Writer {
+	return &Writer{
+		db:    db,
+		in:    in,
+		batch: make([]Suspect, 0, BatchSize),
+		done:  make(chan struct{}),
+	}
+}
+
+// Start begins consuming suspects. Pending records are flushed when ctx is cancelled.
+func (w *Writer) Start(ctx context.Context) {
+	go w.run(ctx)
+}
+
+// Wait blocks until the writer has flushed its final batch.
+func (w *Writer) Wait() {
+	<-w.done
+}
+
+// run accumulates suspects and flushes every BatchSize records or BatchInterval.
+func (w *Writer) run(ctx context.Context) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(BatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.drain()
+			w.flush()
+			return
+		case suspect, ok := <-w.in:
+			if !ok {
+				w.flush()
+				return
+			}
+			w.batch = append(w.batch, suspect)
+			if len(w.batch) >= BatchSize {
+				w.flush()
+			}
+		case <-ticker.C:
+			w.flush()
+		}
+	}
+}
+
+// drain collects any suspects already buffered in the input channel.
+func (w *Writer) drain() {
+	for {
+		select {
+		case suspect, ok := <-w.in:
+			if !ok {
+				return
+			}
+			w.batch = append(w.batch, suspect)
+		default:
+			return
+		}
+	}
+}
+
+// flush writes the current batch to the database.
+func (w *Writer) flush() {
+	if len(w.batch) == 0 {
+		return
+	}
+
+	start := time.Now()
+	if err := w.db.InsertSuspects(w.batch); err != nil {
+		slog.Error("db_write_failed", "count", len(w.batch), "error", err)
+	} else {
+		slog.Debug("db_batch_written", "count", len(w.batch), "latency_ms", time.Since(start).Milliseconds())
+	}
+
+	w.batch = w.batch[:0]
+}
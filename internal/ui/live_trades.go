@@ -85,9 +85,11 @@ func (v *LiveTradesView) updateTable() {
 		// Format time
 		timeStr := trade.Timestamp.Format("15:04:05")
 		
-		// Truncate market
+		// Prefer the market question, fall back to a truncated ID
 		market := trade.MarketID
-		if len(market) > 16 {
+		if trade.Question != "" {
+			market = truncateRunes(trade.Question, 30)
+		} else if len(market) > 16 {
 			market = market[:8] + "..." + market[len(market)-4:]
 		}
 		
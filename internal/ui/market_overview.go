@@ -77,10 +77,7 @@ func (v *MarketOverviewView) Update(snapshot metrics.MetricsSnapshot) {
 		row := i + 1
 		
 		// Truncate question
-		question := market.Question
-		if len(question) > 30 {
-			question = question[:27] + "..."
-		}
+		question := truncateRunes(market.Question, 30)
 		
 		// Format time ago
 		timeAgo := formatTimeAgo(market.LastUpdate)
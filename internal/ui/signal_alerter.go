@@ -87,9 +87,24 @@ func (v *SignalAlerterView) formatSuspect(suspect store.Suspect) (string, string
 	case store.SignalWhale:
 		icon = "🐋"
 		color = tcell.ColorBlue
+	case store.SignalAccumulation:
+		icon = "🧺"
+		color = tcell.ColorOrange
+	case store.SignalRelativeWhale:
+		icon = "🦈"
+		color = tcell.ColorDarkCyan
 	case store.SignalPanicBurst:
 		icon = "⚡"
 		color = tcell.ColorYellow
+	case store.SignalFreshSwarm:
+		icon = "🐝"
+		color = tcell.ColorFuchsia
+	case store.SignalWashTrade:
+		icon = "🔁"
+		color = tcell.ColorGray
+	case store.SignalVolumeAnomaly:
+		icon = "📊"
+		color = tcell.ColorPurple
 	case store.SignalPriceShock:
 		icon = "📈"
 		color = tcell.ColorGreen
@@ -104,9 +119,11 @@ func (v *SignalAlerterView) formatSuspect(suspect store.Suspect) (string, string
 	// Truncate wallet address
 	wallet := truncateAddress(suspect.Trade.MakerAddress)
 	
-	// Truncate market
+	// Prefer the market question, fall back to a truncated ID
 	market := suspect.Trade.MarketID
-	if len(market) > 20 {
+	if suspect.Trade.Question != "" {
+		market = truncateRunes(suspect.Trade.Question, 40)
+	} else if len(market) > 20 {
 		market = market[:8] + "..." + market[len(market)-8:]
 	}
 	
@@ -121,12 +138,39 @@ func (v *SignalAlerterView) formatSuspect(suspect store.Suspect) (string, string
 	if suspect.Nonce >= 0 {
 		secondaryText += fmt.Sprintf(" | Nonce: %d", suspect.Nonce)
 	}
+	if days, ok := suspect.Meta["wallet_age_days"].(float64); ok {
+		secondaryText += fmt.Sprintf(" | Age: %.1fd", days)
+	}
+	
+	// Add funder and its cluster if traced
+	if suspect.Funder != "" {
+		if suspect.ClusterSize > 1 {
+			secondaryText += fmt.Sprintf(" | One of %d funded by %s", suspect.ClusterSize, truncateAddress(suspect.Funder))
+		} else {
+			secondaryText += " | Funded by " + truncateAddress(suspect.Funder)
+		}
+	}
 	
 	// Add meta info if available (e.g., price change for PRICE_SHOCK)
 	if len(suspect.Meta) > 0 {
 		if pctChange, ok := suspect.Meta["pct_change"].(float64); ok {
 			secondaryText += fmt.Sprintf(" | Δ%.2f%%", pctChange*100)
 		}
+		if count, ok := suspect.Meta["wallet_count"].(int); ok {
+			secondaryText += fmt.Sprintf(" | %d wallets", count)
+		}
+		if pattern, ok := suspect.Meta["pattern"].(string); ok {
+			secondaryText += " | " + pattern
+		}
+		if net, ok := suspect.Meta["net_usd"].(float64); ok {
+			secondaryText += fmt.Sprintf(" | Net $%.0f", net)
+		}
+		if multiple, ok := suspect.Meta["volume_multiple"].(float64); ok {
+			secondaryText += fmt.Sprintf(" | %.0fx normal volume", multiple)
+		}
+		if ratio, ok := suspect.Meta["volume_ratio"].(float64); ok {
+			secondaryText += fmt.Sprintf(" | %.1f%% of volume", ratio*100)
+		}
 	}
 	
 	return mainText, secondaryText, color
@@ -140,3 +184,12 @@ func truncateAddress(addr string) string {
 	return addr[:6] + "..." + addr[len(addr)-4:]
 }
 
+// truncateRunes shortens s to at most max characters, ending in "...".
+// It counts runes so multi-byte characters in questions are never split.
+func truncateRunes(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-3]) + "..."
+}
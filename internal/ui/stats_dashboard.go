@@ -41,8 +41,20 @@ func (v *StatsDashboardView) Update(snapshot metrics.MetricsSnapshot) {
 	// Format WebSocket status
 	wsStatus := snapshot.WebSocketStatus
 	wsColor := "red"
-	if wsStatus == "connected" {
+	switch wsStatus {
+	case "connected":
 		wsColor = "green"
+	case "degraded":
+		wsColor = "yellow"
+	}
+	if len(snapshot.WSShards) > 1 {
+		up := 0
+		for _, shard := range snapshot.WSShards {
+			if shard.Status == "connected" {
+				up++
+			}
+		}
+		wsStatus = fmt.Sprintf("%s (%d/%d shards)", wsStatus, up, len(snapshot.WSShards))
 	}
 	
 	// Format REST API status
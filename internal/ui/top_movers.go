@@ -83,10 +83,7 @@ func (v *TopMoversView) Update(snapshot metrics.MetricsSnapshot) {
 		row := i + 1
 		
 		// Truncate question
-		question := mover.Question
-		if len(question) > 25 {
-			question = question[:22] + "..."
-		}
+		question := truncateRunes(mover.Question, 25)
 		
 		// Format price change with color
 		changeStr := fmt.Sprintf("%+.2f%%", mover.PriceChange)